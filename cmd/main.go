@@ -1,8 +1,14 @@
 package main
 
 import(
+	"os"
 	"time"
+	"errors"
 	"context"
+	"strconv"
+
+	// the zoneinfo of the calendar windows, the image may not have it
+	_ "time/tzdata"
 	
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -12,7 +18,12 @@ import(
 	"github.com/go-limit/internal/core/service"
 	"github.com/go-limit/internal/infra/server"
 	"github.com/go-limit/internal/adapter/api"
+	"github.com/go-limit/internal/core/port"
 	"github.com/go-limit/internal/adapter/database"
+	"github.com/go-limit/internal/adapter/memory"
+	"github.com/go-limit/internal/adapter/redis"
+	"github.com/go-limit/internal/adapter/fx"
+	"github.com/go-limit/internal/infra/migration"
 
 	go_core_pg "github.com/eliezerraj/go-core/database/pg"
 )
@@ -32,12 +43,19 @@ func init(){
 
 	infoPod, server := configuration.GetInfoPod()
 	configOTEL 		:= configuration.GetOtelEnv()
-	databaseConfig 	:= configuration.GetDatabaseEnv() 
+	limitConfig 	:= configuration.GetLimitEnv()
+
+	// the database (and its secrets) is not needed by the in-memory storage
+	var databaseConfig go_core_pg.DatabaseConfig
+	if limitConfig.Storage != "memory" {
+		databaseConfig = configuration.GetDatabaseEnv()
+	}
 
 	appServer.InfoPod = &infoPod
 	appServer.Server = &server
 	appServer.ConfigOTEL = &configOTEL
 	appServer.DatabaseConfig = &databaseConfig
+	appServer.LimitConfig = &limitConfig
 }
 
 // Above main
@@ -47,31 +65,142 @@ func main (){
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Open Database
-	count := 1
 	var err error
-	for {
-		databasePGServer, err = databasePGServer.NewDatabasePGServer(ctx, *appServer.DatabaseConfig)
+	var workerRepository port.WorkerRepository
+	is_migrate := len(os.Args) > 1 && os.Args[1] == "migrate"
+
+	if appServer.LimitConfig.Storage == "memory" {
+		if is_migrate {
+			childLogger.Error().Msg("the in-memory storage has no schema to migrate")
+			os.Exit(1)
+		}
+		childLogger.Info().Msg("using the in-memory storage, nothing is persisted")
+		workerRepository = memory.NewWorkerRepository()
+	} else {
+		// Open Database
+		count := 1
+		for {
+			databasePGServer, err = databasePGServer.NewDatabasePGServer(ctx, *appServer.DatabaseConfig)
+			if err != nil {
+				if count < 3 {
+					log.Error().Err(err).Msg("error open database... trying again !!")
+				} else {
+					log.Error().Err(err).Msg("fatal error open Database aborting")
+					panic(err)
+				}
+				time.Sleep(3 * time.Second) //backoff
+				count = count + 1
+				continue
+			}
+			break
+		}
+
+		// schema migration, as a subcommand (migrate up|down [steps]|version) or at startup
+		migrator, err := migration.NewMigrator(&databasePGServer)
 		if err != nil {
-			if count < 3 {
-				log.Error().Err(err).Msg("error open database... trying again !!")
-			} else {
-				log.Error().Err(err).Msg("fatal error open Database aborting")
+			log.Error().Err(err).Msg("fatal error load migrations")
+			panic(err)
+		}
+		if is_migrate {
+			err = runMigration(ctx, migrator, os.Args[2:])
+			if err != nil {
+				log.Error().Err(err).Msg("fatal error migration")
+				os.Exit(1)
+			}
+			return
+		}
+		if appServer.LimitConfig.MigrateOnStart {
+			err = migrator.Up(ctx)
+			if err != nil {
+				log.Error().Err(err).Msg("fatal error migration")
 				panic(err)
 			}
-			time.Sleep(3 * time.Second) //backoff
-			count = count + 1
-			continue
 		}
-		break
+
+		workerRepository = database.NewWorkerRepository(&databasePGServer)
+	}
+
+	// optional counter store for the hot path
+	var counterStore port.CounterStore
+	if appServer.LimitConfig.CounterStore == "redis" {
+		redisClient, err := redis.NewRedisClient(ctx, *appServer.LimitConfig)
+		if err != nil {
+			log.Error().Err(err).Msg("fatal error open redis aborting")
+			panic(err)
+		}
+		defer redisClient.Close()
+		counterStore = redis.NewCounterStore(redisClient)
+	}
+
+	// optional fx provider for the limits in another currency
+	var fxProvider port.FxProvider
+	if appServer.LimitConfig.FxRates != "" {
+		staticFxProvider, err := fx.NewStaticFxProvider(appServer.LimitConfig.FxRates)
+		if err != nil {
+			log.Error().Err(err).Msg("fatal error load fx rates")
+			panic(err)
+		}
+		fxProvider = staticFxProvider
 	}
 
 	// wire	
-	database := database.NewWorkerRepository(&databasePGServer)
-	workerService := service.NewWorkerService(database)
+	workerService := service.NewWorkerService(workerRepository, counterStore, fxProvider, appServer.LimitConfig.BaseCurrency, appServer.LimitConfig.LedgerBuffer)
 	httpRouters := api.NewHttpRouters(workerService, time.Duration(appServer.Server.CtxTimeout))
 
+	// sweep the expired reservations
+	go workerService.SweepLimitReservation(ctx, time.Duration(appServer.LimitConfig.ReservationSweepInterval) * time.Second)
+
+	// purge the limit transactions out of every window
+	go workerService.RetainLimitTransaction(ctx, 
+											time.Duration(appServer.LimitConfig.RetentionInterval) * time.Second,
+											time.Duration(appServer.LimitConfig.RetentionGrace) * time.Second,
+											appServer.LimitConfig.RetentionBatchSize,
+											appServer.LimitConfig.RetentionArchive)
+
+	// write the ledger when the counter store is used
+	ledgerDone := make(chan struct{})
+	go func() {
+		workerService.WriteLedger(ctx)
+		close(ledgerDone)
+	}()
+
 	// start server
 	httpServer := server.NewHttpAppServer(appServer.Server)
 	httpServer.StartHttpAppServer(ctx, &httpRouters, &appServer)
+
+	// the ledger still queued is written before exiting
+	cancel()
+	<-ledgerDone
+}
+
+// Above run the migrate subcommand
+func runMigration(ctx context.Context, migrator *migration.Migrator, args []string) error {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			intVar, err := strconv.Atoi(args[1])
+			if err != nil || intVar <= 0 {
+				return errors.New("invalid number of steps")
+			}
+			steps = intVar
+		}
+		return migrator.Down(ctx, steps)
+	case "version":
+		version, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		childLogger.Info().Int("schema_version", version).Send()
+		return nil
+	}
+
+	return errors.New("usage: migrate up|down [steps]|version")
 }
\ No newline at end of file
@@ -0,0 +1,667 @@
+package api
+
+import (
+	"fmt"
+	"time"
+	"context"
+	"strconv"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/erro"
+)
+
+// About list all type limit
+func (h *HttpRouters) ListTypeLimit(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListTypeLimit").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.ListTypeLimit")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	res, err := h.workerService.ListTypeLimit(ctx)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About get a type limit
+func (h *HttpRouters) GetTypeLimit(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","GetTypeLimit").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.GetTypeLimit")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+
+	res, err := h.workerService.GetTypeLimit(ctx, model.TypeLimit{Code: vars["id"]})
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About add a type limit
+func (h *HttpRouters) AddTypeLimit(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddTypeLimit").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.AddTypeLimit")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	typeLimit := model.TypeLimit{}
+	err := json.NewDecoder(req.Body).Decode(&typeLimit)
+    if err != nil {
+		return h.ErrorHandler(trace_id, decodeError(err))
+    }
+	defer req.Body.Close()
+
+	res, err := h.workerService.AddTypeLimit(ctx, typeLimit)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusCreated, res)
+}
+
+// About update a type limit
+func (h *HttpRouters) UpdateTypeLimit(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","UpdateTypeLimit").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.UpdateTypeLimit")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+
+	typeLimit := model.TypeLimit{}
+	err := json.NewDecoder(req.Body).Decode(&typeLimit)
+    if err != nil {
+		return h.ErrorHandler(trace_id, decodeError(err))
+    }
+	defer req.Body.Close()
+	typeLimit.Code = vars["id"]
+
+	res, err := h.workerService.UpdateTypeLimit(ctx, typeLimit)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About delete a type limit
+func (h *HttpRouters) DeleteTypeLimit(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","DeleteTypeLimit").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.DeleteTypeLimit")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+
+	err := h.workerService.DeleteTypeLimit(ctx, model.TypeLimit{Code: vars["id"]})
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// About list the order limits, filtered by the query param type_limit
+func (h *HttpRouters) ListOrderLimit(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListOrderLimit").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.ListOrderLimit")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	orderLimit := model.OrderLimit{TypeLimit: req.URL.Query().Get("type_limit")}
+
+	res, err := h.workerService.ListOrderLimit(ctx, orderLimit)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About get an order limit
+func (h *HttpRouters) GetOrderLimit(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","GetOrderLimit").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.GetOrderLimit")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+	}
+
+	res, err := h.workerService.GetOrderLimit(ctx, model.OrderLimit{ID: id})
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About add an order limit
+func (h *HttpRouters) AddOrderLimit(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddOrderLimit").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.AddOrderLimit")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	orderLimit := model.OrderLimit{}
+	err := json.NewDecoder(req.Body).Decode(&orderLimit)
+    if err != nil {
+		return h.ErrorHandler(trace_id, decodeError(err))
+    }
+	defer req.Body.Close()
+
+	res, err := h.workerService.AddOrderLimit(ctx, orderLimit)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusCreated, res)
+}
+
+// About update an order limit
+func (h *HttpRouters) UpdateOrderLimit(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","UpdateOrderLimit").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.UpdateOrderLimit")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+	}
+
+	orderLimit := model.OrderLimit{}
+	err = json.NewDecoder(req.Body).Decode(&orderLimit)
+    if err != nil {
+		return h.ErrorHandler(trace_id, decodeError(err))
+    }
+	defer req.Body.Close()
+	orderLimit.ID = id
+
+	res, err := h.workerService.UpdateOrderLimit(ctx, orderLimit)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About delete an order limit
+func (h *HttpRouters) DeleteOrderLimit(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","DeleteOrderLimit").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.DeleteOrderLimit")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+	}
+
+	err = h.workerService.DeleteOrderLimit(ctx, model.OrderLimit{ID: id})
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// About list the overrides, filtered by the query param key
+func (h *HttpRouters) ListLimitOverride(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListLimitOverride").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.ListLimitOverride")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	limitOverride := model.LimitOverride{Key: req.URL.Query().Get("key")}
+
+	res, err := h.workerService.ListLimitOverride(ctx, limitOverride)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About get an override
+func (h *HttpRouters) GetLimitOverride(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","GetLimitOverride").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.GetLimitOverride")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+	}
+
+	res, err := h.workerService.GetLimitOverride(ctx, model.LimitOverride{ID: id})
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About add an override
+func (h *HttpRouters) AddLimitOverride(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddLimitOverride").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.AddLimitOverride")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	limitOverride := model.LimitOverride{}
+	err := json.NewDecoder(req.Body).Decode(&limitOverride)
+    if err != nil {
+		return h.ErrorHandler(trace_id, decodeError(err))
+    }
+	defer req.Body.Close()
+
+	res, err := h.workerService.AddLimitOverride(ctx, limitOverride)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusCreated, res)
+}
+
+// About update an override
+func (h *HttpRouters) UpdateLimitOverride(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","UpdateLimitOverride").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.UpdateLimitOverride")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+	}
+
+	limitOverride := model.LimitOverride{}
+	err = json.NewDecoder(req.Body).Decode(&limitOverride)
+    if err != nil {
+		return h.ErrorHandler(trace_id, decodeError(err))
+    }
+	defer req.Body.Close()
+	limitOverride.ID = id
+
+	res, err := h.workerService.UpdateLimitOverride(ctx, limitOverride)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About delete an override
+func (h *HttpRouters) DeleteLimitOverride(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","DeleteLimitOverride").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.DeleteLimitOverride")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+	}
+
+	err = h.workerService.DeleteLimitOverride(ctx, model.LimitOverride{ID: id})
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// About list the adjustments, filtered by the query param key
+func (h *HttpRouters) ListLimitAdjustment(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListLimitAdjustment").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.ListLimitAdjustment")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	limitAdjustment := model.LimitAdjustment{Key: req.URL.Query().Get("key")}
+
+	res, err := h.workerService.ListLimitAdjustment(ctx, limitAdjustment)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About get an adjustment
+func (h *HttpRouters) GetLimitAdjustment(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","GetLimitAdjustment").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.GetLimitAdjustment")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+	}
+
+	res, err := h.workerService.GetLimitAdjustment(ctx, model.LimitAdjustment{ID: id})
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About schedule an adjustment
+func (h *HttpRouters) AddLimitAdjustment(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddLimitAdjustment").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.AddLimitAdjustment")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	limitAdjustment := model.LimitAdjustment{}
+	err := json.NewDecoder(req.Body).Decode(&limitAdjustment)
+    if err != nil {
+		return h.ErrorHandler(trace_id, decodeError(err))
+    }
+	defer req.Body.Close()
+
+	res, err := h.workerService.AddLimitAdjustment(ctx, limitAdjustment)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusCreated, res)
+}
+
+// About update an adjustment
+func (h *HttpRouters) UpdateLimitAdjustment(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","UpdateLimitAdjustment").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.UpdateLimitAdjustment")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+	}
+
+	limitAdjustment := model.LimitAdjustment{}
+	err = json.NewDecoder(req.Body).Decode(&limitAdjustment)
+    if err != nil {
+		return h.ErrorHandler(trace_id, decodeError(err))
+    }
+	defer req.Body.Close()
+	limitAdjustment.ID = id
+
+	res, err := h.workerService.UpdateLimitAdjustment(ctx, limitAdjustment)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About delete an adjustment
+func (h *HttpRouters) DeleteLimitAdjustment(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","DeleteLimitAdjustment").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.DeleteLimitAdjustment")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+	}
+
+	err = h.workerService.DeleteLimitAdjustment(ctx, model.LimitAdjustment{ID: id})
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// About list all mcc groups
+func (h *HttpRouters) ListMccGroup(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListMccGroup").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.ListMccGroup")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	res, err := h.workerService.ListMccGroup(ctx)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About get a mcc group
+func (h *HttpRouters) GetMccGroup(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","GetMccGroup").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.GetMccGroup")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+
+	res, err := h.workerService.GetMccGroup(ctx, model.MccGroup{Code: vars["id"]})
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About add a mcc group
+func (h *HttpRouters) AddMccGroup(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddMccGroup").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.AddMccGroup")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	mccGroup := model.MccGroup{}
+	err := json.NewDecoder(req.Body).Decode(&mccGroup)
+    if err != nil {
+		return h.ErrorHandler(trace_id, decodeError(err))
+    }
+	defer req.Body.Close()
+
+	res, err := h.workerService.AddMccGroup(ctx, mccGroup)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusCreated, res)
+}
+
+// About update a mcc group
+func (h *HttpRouters) UpdateMccGroup(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","UpdateMccGroup").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.UpdateMccGroup")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+
+	mccGroup := model.MccGroup{}
+	err := json.NewDecoder(req.Body).Decode(&mccGroup)
+    if err != nil {
+		return h.ErrorHandler(trace_id, decodeError(err))
+    }
+	defer req.Body.Close()
+	mccGroup.Code = vars["id"]
+
+	res, err := h.workerService.UpdateMccGroup(ctx, mccGroup)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About delete a mcc group
+func (h *HttpRouters) DeleteMccGroup(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","DeleteMccGroup").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.DeleteMccGroup")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	vars := mux.Vars(req)
+
+	err := h.workerService.DeleteMccGroup(ctx, model.MccGroup{Code: vars["id"]})
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"errors"
 	"time"
 	"context"
 	"encoding/json"
@@ -79,16 +80,33 @@ func (h *HttpRouters) Stat(rw http.ResponseWriter, req *http.Request) {
 	json.NewEncoder(rw).Encode(res)
 }
 
+// About the error of a body that could not be decoded, an invalid amount is reported as is
+func decodeError(err error) error {
+	if errors.Is(err, erro.ErrInvalidAmount) {
+		return erro.ErrInvalidAmount
+	}
+	return erro.ErrBadRequest
+}
+
 // About handle error
 func (h *HttpRouters) ErrorHandler(trace_id string, err error) *coreJson.APIError {
 	if strings.Contains(err.Error(), "context deadline exceeded") {
     	err = erro.ErrTimeout
 	} 
+	// the message of an invalid expression has the position of the error
+	if errors.Is(err, erro.ErrExpression) {
+		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusBadRequest)
+		return &core_apiError
+	}
 	switch err {
-	case erro.ErrBadRequest:
+	case erro.ErrBadRequest, erro.ErrInvalidAmount, erro.ErrInvalidWindow:
 		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusBadRequest)
 	case erro.ErrNotFound:
 		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusNotFound)
+	case erro.ErrConflict:
+		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusConflict)
+	case erro.ErrFxRate:
+		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusUnprocessableEntity)
 	case erro.ErrTimeout:
 		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusGatewayTimeout)
 	default:
@@ -112,7 +130,7 @@ func (h *HttpRouters) CheckLimitTransaction(rw http.ResponseWriter, req *http.Re
 	limit := model.Limit{}
 	err := json.NewDecoder(req.Body).Decode(&limit)
     if err != nil {
-		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+		return h.ErrorHandler(trace_id, decodeError(err))
     }
 	defer req.Body.Close()
 
@@ -121,5 +139,113 @@ func (h *HttpRouters) CheckLimitTransaction(rw http.ResponseWriter, req *http.Re
 		return h.ErrorHandler(trace_id, err)
 	}
 	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About simulate a check, the same response of a check without consuming the limit
+func (h *HttpRouters) SimulateLimitTransaction(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","SimulateLimitTransaction").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.SimulateLimitTransaction")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	limit := model.Limit{}
+	err := json.NewDecoder(req.Body).Decode(&limit)
+    if err != nil {
+		return h.ErrorHandler(trace_id, decodeError(err))
+    }
+	defer req.Body.Close()
+
+	res, err := h.workerService.SimulateLimitTransaction(ctx, limit)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About reverse a limit transaction
+func (h *HttpRouters) ReverseLimitTransaction(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ReverseLimitTransaction").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.ReverseLimitTransaction")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	limitReversal := model.LimitReversal{}
+	err := json.NewDecoder(req.Body).Decode(&limitReversal)
+    if err != nil {
+		return h.ErrorHandler(trace_id, decodeError(err))
+    }
+	defer req.Body.Close()
+
+	res, err := h.workerService.ReverseLimitTransaction(ctx, limitReversal)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About confirm a reservation of limit
+func (h *HttpRouters) ConfirmLimitTransaction(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ConfirmLimitTransaction").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.ConfirmLimitTransaction")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	limitTransaction := model.LimitTransaction{}
+	err := json.NewDecoder(req.Body).Decode(&limitTransaction)
+    if err != nil {
+		return h.ErrorHandler(trace_id, decodeError(err))
+    }
+	defer req.Body.Close()
+
+	res, err := h.workerService.ConfirmLimitTransaction(ctx, limitTransaction)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About release a reservation of limit
+func (h *HttpRouters) ReleaseLimitTransaction(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ReleaseLimitTransaction").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.api.ReleaseLimitTransaction")
+	defer span.End()
+
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	limitTransaction := model.LimitTransaction{}
+	err := json.NewDecoder(req.Body).Decode(&limitTransaction)
+    if err != nil {
+		return h.ErrorHandler(trace_id, decodeError(err))
+    }
+	defer req.Body.Close()
+
+	res, err := h.workerService.ReleaseLimitTransaction(ctx, limitTransaction)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	
 	return core_json.WriteJSON(rw, http.StatusOK, res)
 }
\ No newline at end of file
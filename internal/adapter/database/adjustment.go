@@ -0,0 +1,223 @@
+package database
+
+import (
+	"context"
+	"time"
+	"errors"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/erro"
+)
+
+// Above get the adjustments of a key active now
+func (w WorkerRepository) GetLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) (*[]model.LimitAdjustment, error){
+	childLogger.Info().Str("func","GetLimitAdjustment").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.GetLimitAdjustment")
+	defer span.End()
+
+	return w.listLimitAdjustment(ctx, `where key = $1 and starts_at <= now() and ends_at > now()`, limitAdjustment.Key)
+}
+
+// Above get an adjustment by id
+func (w WorkerRepository) GetLimitAdjustmentById(ctx context.Context, limitAdjustment model.LimitAdjustment) (*model.LimitAdjustment, error){
+	childLogger.Info().Str("func","GetLimitAdjustmentById").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.GetLimitAdjustmentById")
+	defer span.End()
+
+	res_list_limit_adjustment, err := w.listLimitAdjustment(ctx, `where id = $1`, limitAdjustment.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(*res_list_limit_adjustment) == 0 {
+		return nil, erro.ErrNotFound
+	}
+
+	return &(*res_list_limit_adjustment)[0], nil
+}
+
+// Above list the adjustments (past and scheduled included), all of them or only the ones of a key
+func (w WorkerRepository) ListLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) (*[]model.LimitAdjustment, error){
+	childLogger.Info().Str("func","ListLimitAdjustment").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.ListLimitAdjustment")
+	defer span.End()
+
+	if limitAdjustment.Key != "" {
+		return w.listLimitAdjustment(ctx, `where key = $1`, limitAdjustment.Key)
+	}
+	return w.listLimitAdjustment(ctx, ``)
+}
+
+// Above query the adjustments with a filter
+func (w WorkerRepository) listLimitAdjustment(ctx context.Context, filter string, args ...any) (*[]model.LimitAdjustment, error){
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// prepare query
+	res_list_limit_adjustment := []model.LimitAdjustment{}
+
+	query := `select id,
+					 key,
+					 fk_order_limit_id,
+					 type,
+					 value,
+					 starts_at,
+					 ends_at,
+					 created_at
+			  from limit_adjustment ` + filter + `
+			  order by id`
+
+	rows, err := conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	// execute
+	for rows.Next() {
+		res_limit_adjustment := model.LimitAdjustment{}
+
+		err := rows.Scan( 	&res_limit_adjustment.ID,
+							&res_limit_adjustment.Key,
+							&res_limit_adjustment.OrderLimitId,
+							&res_limit_adjustment.Type,
+							&res_limit_adjustment.Value,
+							&res_limit_adjustment.StartAt,
+							&res_limit_adjustment.EndAt,
+							&res_limit_adjustment.CreateAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+
+		res_list_limit_adjustment = append(res_list_limit_adjustment, res_limit_adjustment)
+	}
+
+	return &res_list_limit_adjustment, nil
+}
+
+// Above add an adjustment
+func (w WorkerRepository) AddLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) (*model.LimitAdjustment, error){
+	childLogger.Info().Str("func","AddLimitAdjustment").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddLimitAdjustment")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// prepare
+	limitAdjustment.CreateAt = time.Now()
+
+	//query
+	query := `INSERT INTO limit_adjustment (key,
+										   fk_order_limit_id,
+										   type,
+										   value,
+										   starts_at,
+										   ends_at,
+										   created_at)
+										   VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	// execute
+	row := conn.QueryRow(ctx, query,	limitAdjustment.Key,
+										limitAdjustment.OrderLimitId,
+										limitAdjustment.Type,
+										limitAdjustment.Value,
+										limitAdjustment.StartAt,
+										limitAdjustment.EndAt,
+										limitAdjustment.CreateAt,
+										)
+
+	var id int
+
+	if err := row.Scan(&id); err != nil {
+		return nil, pgError(err)
+	}
+
+	limitAdjustment.ID = id
+
+	return &limitAdjustment, nil
+}
+
+// Above update an adjustment
+func (w WorkerRepository) UpdateLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) (int64, error){
+	childLogger.Info().Str("func","UpdateLimitAdjustment").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.UpdateLimitAdjustment")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	//query
+	query := `update limit_adjustment
+				set key = $2,
+					fk_order_limit_id = $3,
+					type = $4,
+					value = $5,
+					starts_at = $6,
+					ends_at = $7
+				where id = $1`
+
+	// execute
+	row, err := conn.Exec(ctx, query,	limitAdjustment.ID,
+										limitAdjustment.Key,
+										limitAdjustment.OrderLimitId,
+										limitAdjustment.Type,
+										limitAdjustment.Value,
+										limitAdjustment.StartAt,
+										limitAdjustment.EndAt,
+										)
+	if err != nil {
+		return 0, pgError(err)
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// Above delete an adjustment
+func (w WorkerRepository) DeleteLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) (int64, error){
+	childLogger.Info().Str("func","DeleteLimitAdjustment").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.DeleteLimitAdjustment")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	//query
+	query := `delete from limit_adjustment where id = $1`
+
+	// execute
+	row, err := conn.Exec(ctx, query, limitAdjustment.ID)
+	if err != nil {
+		return 0, pgError(err)
+	}
+
+	return row.RowsAffected(), nil
+}
@@ -0,0 +1,454 @@
+package database
+
+import (
+	"context"
+	"time"
+	"errors"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/erro"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Above convert a postgres error, an unique or a foreign key violation is a conflict
+func pgError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505", "23503":
+			return erro.ErrConflict
+		}
+	}
+	return errors.New(err.Error())
+}
+
+// Above list all type limit
+func (w WorkerRepository) ListTypeLimit(ctx context.Context) (*[]model.TypeLimit, error){
+	childLogger.Info().Str("func","ListTypeLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.ListTypeLimit")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// prepare query
+	res_list_type_limit := []model.TypeLimit{}
+
+	query := `select code,
+					 category,
+					 timezone,
+					 billing_cycle_day,
+					 created_at
+			  from type_limit
+			  order by code`
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	// execute
+	for rows.Next() {
+		res_type_limit := model.TypeLimit{}
+
+		err := rows.Scan( 	&res_type_limit.Code,
+							&res_type_limit.Category,
+							&res_type_limit.Timezone,
+							&res_type_limit.BillingCycleDay,
+							&res_type_limit.CreateAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+
+		res_list_type_limit = append(res_list_type_limit, res_type_limit)
+	}
+
+	return &res_list_type_limit, nil
+}
+
+// Above add a type limit
+func (w WorkerRepository) AddTypeLimit(ctx context.Context, typeLimit model.TypeLimit) (*model.TypeLimit, error){
+	childLogger.Info().Str("func","AddTypeLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddTypeLimit")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// prepare, a type limit without timezone is in UTC and its billing cycle starts on the first day
+	typeLimit.CreateAt = time.Now()
+	if typeLimit.Timezone == "" {
+		typeLimit.Timezone = "UTC"
+	}
+	if typeLimit.BillingCycleDay == 0 {
+		typeLimit.BillingCycleDay = 1
+	}
+
+	//query
+	query := `INSERT INTO type_limit (code,
+									 category,
+									 timezone,
+									 billing_cycle_day,
+									 created_at)
+									 VALUES($1, $2, $3, $4, $5)`
+
+	// execute
+	_, err = conn.Exec(ctx, query,	typeLimit.Code,
+									typeLimit.Category,
+									typeLimit.Timezone,
+									typeLimit.BillingCycleDay,
+									typeLimit.CreateAt,
+									)
+	if err != nil {
+		return nil, pgError(err)
+	}
+
+	return &typeLimit, nil
+}
+
+// Above update a type limit
+func (w WorkerRepository) UpdateTypeLimit(ctx context.Context, typeLimit model.TypeLimit) (int64, error){
+	childLogger.Info().Str("func","UpdateTypeLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.UpdateTypeLimit")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	//query
+	query := `update type_limit
+				set category = $2,
+					timezone = coalesce(nullif($3, ''), 'UTC'),
+					billing_cycle_day = coalesce(nullif($4, 0), 1)
+				where code = $1`
+
+	// execute
+	row, err := conn.Exec(ctx, query,	typeLimit.Code,
+										typeLimit.Category,
+										typeLimit.Timezone,
+										typeLimit.BillingCycleDay,
+										)
+	if err != nil {
+		return 0, pgError(err)
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// Above delete a type limit, it is a conflict while there are order limits using it
+func (w WorkerRepository) DeleteTypeLimit(ctx context.Context, typeLimit model.TypeLimit) (int64, error){
+	childLogger.Info().Str("func","DeleteTypeLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.DeleteTypeLimit")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	//query
+	query := `delete from type_limit where code = $1`
+
+	// execute
+	row, err := conn.Exec(ctx, query, typeLimit.Code)
+	if err != nil {
+		return 0, pgError(err)
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// Above get a counter limit
+func (w WorkerRepository) GetCounterLimit(ctx context.Context, counterLimit model.CounterLimit) (*model.CounterLimit, error){
+	childLogger.Info().Str("func","GetCounterLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.GetCounterLimit")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// prepare query
+	res_counter_limit := model.CounterLimit{}
+
+	query := `select code
+			  from counter_limit
+			  where code = $1`
+
+	rows, err := conn.Query(ctx,
+							query,
+							counterLimit.Code)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	// execute
+	for rows.Next() {
+		err := rows.Scan( &res_counter_limit.Code )
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		return &res_counter_limit, nil
+	}
+
+	return nil, erro.ErrNotFound
+}
+
+// Above get an order limit by id
+func (w WorkerRepository) GetOrderLimitById(ctx context.Context, orderLimit model.OrderLimit) (*model.OrderLimit, error){
+	childLogger.Info().Str("func","GetOrderLimitById").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.GetOrderLimitById")
+	defer span.End()
+
+	res_list_order_limit, err := w.listOrderLimit(ctx, `where id = $1`, orderLimit.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(*res_list_order_limit) == 0 {
+		return nil, erro.ErrNotFound
+	}
+
+	return &(*res_list_order_limit)[0], nil
+}
+
+// Above list the order limits, all of them or only the ones of a type limit
+func (w WorkerRepository) ListOrderLimit(ctx context.Context, orderLimit model.OrderLimit) (*[]model.OrderLimit, error){
+	childLogger.Info().Str("func","ListOrderLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.ListOrderLimit")
+	defer span.End()
+
+	if orderLimit.TypeLimit != "" {
+		return w.listOrderLimit(ctx, `where fk_type_limit_code = $1`, orderLimit.TypeLimit)
+	}
+	return w.listOrderLimit(ctx, ``)
+}
+
+// Above query the order limits with a filter
+func (w WorkerRepository) listOrderLimit(ctx context.Context, filter string, args ...any) (*[]model.OrderLimit, error){
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// prepare query
+	res_list_order_limit := []model.OrderLimit{}
+
+	query := `select id,
+					 fk_type_limit_code,
+					 fk_counter_limit_code,
+					 type,
+					 amount,
+					 time_window,
+					 created_at,
+					 currency,
+					 mcc,
+					 conditions,
+					 expression
+			  from order_limit ` + filter + `
+			  order by id`
+
+	rows, err := conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	// execute
+	for rows.Next() {
+		res_order_limit := model.OrderLimit{}
+
+		err := rows.Scan( 	&res_order_limit.ID,
+							&res_order_limit.TypeLimit,
+							&res_order_limit.CounterLimit,
+							&res_order_limit.Type,
+							&res_order_limit.Amount,
+							&res_order_limit.Window,
+							&res_order_limit.CreateAt,
+							&res_order_limit.Currency,
+							&res_order_limit.Mcc,
+							&res_order_limit.Conditions,
+							&res_order_limit.Expression,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+
+		res_list_order_limit = append(res_list_order_limit, res_order_limit)
+	}
+
+	return &res_list_order_limit, nil
+}
+
+// Above the conditions of an order limit as stored, an order limit without conditions has an empty list
+func orderLimitConditions(orderLimit model.OrderLimit) []model.LimitCondition {
+	if orderLimit.Conditions == nil {
+		return []model.LimitCondition{}
+	}
+	return orderLimit.Conditions
+}
+
+// Above add an order limit
+func (w WorkerRepository) AddOrderLimit(ctx context.Context, orderLimit model.OrderLimit) (*model.OrderLimit, error){
+	childLogger.Info().Str("func","AddOrderLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddOrderLimit")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// prepare
+	orderLimit.CreateAt = time.Now()
+
+	//query
+	query := `INSERT INTO order_limit (fk_type_limit_code,
+									  fk_counter_limit_code,
+									  type,
+									  amount,
+									  time_window,
+									  created_at,
+									  currency,
+									  mcc,
+									  conditions,
+									  expression)
+									  VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+
+	// execute
+	row := conn.QueryRow(ctx, query,	orderLimit.TypeLimit,
+										orderLimit.CounterLimit,
+										orderLimit.Type,
+										orderLimit.Amount,
+										orderLimit.Window,
+										orderLimit.CreateAt,
+										orderLimit.Currency,
+										orderLimit.Mcc,
+										orderLimitConditions(orderLimit),
+										orderLimit.Expression,
+										)
+
+	var id int
+
+	if err := row.Scan(&id); err != nil {
+		return nil, pgError(err)
+	}
+
+	orderLimit.ID = id
+
+	return &orderLimit, nil
+}
+
+// Above update an order limit
+func (w WorkerRepository) UpdateOrderLimit(ctx context.Context, orderLimit model.OrderLimit) (int64, error){
+	childLogger.Info().Str("func","UpdateOrderLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.UpdateOrderLimit")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	//query
+	query := `update order_limit
+				set fk_type_limit_code = $2,
+					fk_counter_limit_code = $3,
+					type = $4,
+					amount = $5,
+					time_window = $6,
+					currency = $7,
+					mcc = $8,
+					conditions = $9,
+					expression = $10
+				where id = $1`
+
+	// execute
+	row, err := conn.Exec(ctx, query,	orderLimit.ID,
+										orderLimit.TypeLimit,
+										orderLimit.CounterLimit,
+										orderLimit.Type,
+										orderLimit.Amount,
+										orderLimit.Window,
+										orderLimit.Currency,
+										orderLimit.Mcc,
+										orderLimitConditions(orderLimit),
+										orderLimit.Expression,
+										)
+	if err != nil {
+		return 0, pgError(err)
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// Above delete an order limit
+func (w WorkerRepository) DeleteOrderLimit(ctx context.Context, orderLimit model.OrderLimit) (int64, error){
+	childLogger.Info().Str("func","DeleteOrderLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.DeleteOrderLimit")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	//query
+	query := `delete from order_limit where id = $1`
+
+	// execute
+	row, err := conn.Exec(ctx, query, orderLimit.ID)
+	if err != nil {
+		return 0, pgError(err)
+	}
+
+	return row.RowsAffected(), nil
+}
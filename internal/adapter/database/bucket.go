@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/port"
+)
+
+// Above the width of a bucket of the rolling counters
+const bucketWidth = time.Minute
+
+// Above the start of the first bucket entirely inside a window
+// The rows before it (the edge of the window) are read from limit_transaction
+func bucketStart(windowStart time.Time) time.Time {
+	bucket_start := windowStart.Truncate(bucketWidth)
+	if bucket_start.Before(windowStart) {
+		bucket_start = bucket_start.Add(bucketWidth)
+	}
+	return bucket_start
+}
+
+// Above check if a limit transaction is counted by the buckets
+// A reservation enters the buckets only when it is confirmed, a breach, decline, release or expiration never does
+func isBucketStatus(status string) bool {
+	for _, suffix := range []string{":BREACH", ":DECLINED", ":RESERVED", ":RELEASED", ":EXPIRED"} {
+		if strings.HasSuffix(status, suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+// Above add a limit transaction to its bucket (same transaction as the limit transaction)
+func (w WorkerRepository) addCounterBucket(ctx context.Context, tx port.Tx, limitTransaction model.LimitTransaction) error{
+	// trace
+	span := tracerProvider.Span(ctx, "database.addCounterBucket")
+	defer span.End()
+
+	//query
+	query := `insert into limit_counter_bucket (key,
+												fk_type_limit_code,
+												fk_order_limit_type,
+												fk_counter_limit_code,
+												mcc,
+												rule_id,
+												time_window,
+												bucket_start,
+												amount,
+												quantity,
+												first_created_at)
+				values ($1, $2, $3, $4, $5, $6, $7, $8, $9, 1, $10)
+				on conflict (key, fk_type_limit_code, fk_order_limit_type, fk_counter_limit_code, mcc, rule_id, time_window, bucket_start)
+				do update set amount = limit_counter_bucket.amount + excluded.amount,
+							  quantity = limit_counter_bucket.quantity + 1,
+							  first_created_at = least(limit_counter_bucket.first_created_at, excluded.first_created_at)`
+
+	// execute
+	_, err := pgxTx(tx).Exec(ctx, query,	limitTransaction.Key,
+										limitTransaction.TypeLimit,
+										limitTransaction.OrderLimit,
+										limitTransaction.CounterLimit,
+										limitTransaction.Mcc,
+										limitTransaction.RuleId,
+										limitTransaction.Window,
+										limitTransaction.CreareAt.Truncate(bucketWidth),
+										limitTransaction.Amount,
+										limitTransaction.CreareAt,
+										)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package database
+
+import (
+	"context"
+	"time"
+	"errors"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/erro"
+)
+
+// Above get a mcc group
+func (w WorkerRepository) GetMccGroup(ctx context.Context, mccGroup model.MccGroup) (*model.MccGroup, error){
+	childLogger.Info().Str("func","GetMccGroup").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.GetMccGroup")
+	defer span.End()
+
+	res_list_mcc_group, err := w.listMccGroup(ctx, `where code = $1`, mccGroup.Code)
+	if err != nil {
+		return nil, err
+	}
+	if len(*res_list_mcc_group) == 0 {
+		return nil, erro.ErrNotFound
+	}
+
+	return &(*res_list_mcc_group)[0], nil
+}
+
+// Above list all mcc groups
+func (w WorkerRepository) ListMccGroup(ctx context.Context) (*[]model.MccGroup, error){
+	childLogger.Info().Str("func","ListMccGroup").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.ListMccGroup")
+	defer span.End()
+
+	return w.listMccGroup(ctx, ``)
+}
+
+// Above query the mcc groups with a filter
+func (w WorkerRepository) listMccGroup(ctx context.Context, filter string, args ...any) (*[]model.MccGroup, error){
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// prepare query
+	res_list_mcc_group := []model.MccGroup{}
+
+	query := `select code,
+					 mccs,
+					 created_at
+			  from mcc_group ` + filter + `
+			  order by code`
+
+	rows, err := conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	// execute
+	for rows.Next() {
+		res_mcc_group := model.MccGroup{}
+
+		err := rows.Scan( 	&res_mcc_group.Code,
+							&res_mcc_group.Mccs,
+							&res_mcc_group.CreateAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+
+		res_list_mcc_group = append(res_list_mcc_group, res_mcc_group)
+	}
+
+	return &res_list_mcc_group, nil
+}
+
+// Above add a mcc group
+func (w WorkerRepository) AddMccGroup(ctx context.Context, mccGroup model.MccGroup) (*model.MccGroup, error){
+	childLogger.Info().Str("func","AddMccGroup").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddMccGroup")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// prepare
+	mccGroup.CreateAt = time.Now()
+
+	//query
+	query := `INSERT INTO mcc_group (code,
+									mccs,
+									created_at)
+									VALUES($1, $2, $3)`
+
+	// execute
+	_, err = conn.Exec(ctx, query,	mccGroup.Code,
+									mccGroup.Mccs,
+									mccGroup.CreateAt,
+									)
+	if err != nil {
+		return nil, pgError(err)
+	}
+
+	return &mccGroup, nil
+}
+
+// Above update the mccs of a mcc group
+func (w WorkerRepository) UpdateMccGroup(ctx context.Context, mccGroup model.MccGroup) (int64, error){
+	childLogger.Info().Str("func","UpdateMccGroup").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.UpdateMccGroup")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	//query
+	query := `update mcc_group
+				set mccs = $2
+				where code = $1`
+
+	// execute
+	row, err := conn.Exec(ctx, query,	mccGroup.Code,
+										mccGroup.Mccs,
+										)
+	if err != nil {
+		return 0, pgError(err)
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// Above delete a mcc group
+func (w WorkerRepository) DeleteMccGroup(ctx context.Context, mccGroup model.MccGroup) (int64, error){
+	childLogger.Info().Str("func","DeleteMccGroup").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.DeleteMccGroup")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	//query
+	query := `delete from mcc_group where code = $1`
+
+	// execute
+	row, err := conn.Exec(ctx, query, mccGroup.Code)
+	if err != nil {
+		return 0, pgError(err)
+	}
+
+	return row.RowsAffected(), nil
+}
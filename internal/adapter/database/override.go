@@ -0,0 +1,211 @@
+package database
+
+import (
+	"context"
+	"time"
+	"errors"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/erro"
+)
+
+// Above get the overrides of a key still active (not expired)
+func (w WorkerRepository) GetLimitOverride(ctx context.Context, limitOverride model.LimitOverride) (*[]model.LimitOverride, error){
+	childLogger.Info().Str("func","GetLimitOverride").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.GetLimitOverride")
+	defer span.End()
+
+	return w.listLimitOverride(ctx, `where key = $1 and (expires_at is null or expires_at > now())`, limitOverride.Key)
+}
+
+// Above get an override by id
+func (w WorkerRepository) GetLimitOverrideById(ctx context.Context, limitOverride model.LimitOverride) (*model.LimitOverride, error){
+	childLogger.Info().Str("func","GetLimitOverrideById").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.GetLimitOverrideById")
+	defer span.End()
+
+	res_list_limit_override, err := w.listLimitOverride(ctx, `where id = $1`, limitOverride.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(*res_list_limit_override) == 0 {
+		return nil, erro.ErrNotFound
+	}
+
+	return &(*res_list_limit_override)[0], nil
+}
+
+// Above list the overrides (expired included), all of them or only the ones of a key
+func (w WorkerRepository) ListLimitOverride(ctx context.Context, limitOverride model.LimitOverride) (*[]model.LimitOverride, error){
+	childLogger.Info().Str("func","ListLimitOverride").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.ListLimitOverride")
+	defer span.End()
+
+	if limitOverride.Key != "" {
+		return w.listLimitOverride(ctx, `where key = $1`, limitOverride.Key)
+	}
+	return w.listLimitOverride(ctx, ``)
+}
+
+// Above query the overrides with a filter
+func (w WorkerRepository) listLimitOverride(ctx context.Context, filter string, args ...any) (*[]model.LimitOverride, error){
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// prepare query
+	res_list_limit_override := []model.LimitOverride{}
+
+	query := `select id,
+					 key,
+					 fk_order_limit_id,
+					 amount,
+					 expires_at,
+					 created_at
+			  from limit_override ` + filter + `
+			  order by id`
+
+	rows, err := conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	// execute
+	for rows.Next() {
+		res_limit_override := model.LimitOverride{}
+
+		err := rows.Scan( 	&res_limit_override.ID,
+							&res_limit_override.Key,
+							&res_limit_override.OrderLimitId,
+							&res_limit_override.Amount,
+							&res_limit_override.ExpireAt,
+							&res_limit_override.CreateAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+
+		res_list_limit_override = append(res_list_limit_override, res_limit_override)
+	}
+
+	return &res_list_limit_override, nil
+}
+
+// Above add an override
+func (w WorkerRepository) AddLimitOverride(ctx context.Context, limitOverride model.LimitOverride) (*model.LimitOverride, error){
+	childLogger.Info().Str("func","AddLimitOverride").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddLimitOverride")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// prepare
+	limitOverride.CreateAt = time.Now()
+
+	//query
+	query := `INSERT INTO limit_override (key,
+										 fk_order_limit_id,
+										 amount,
+										 expires_at,
+										 created_at)
+										 VALUES($1, $2, $3, $4, $5) RETURNING id`
+
+	// execute
+	row := conn.QueryRow(ctx, query,	limitOverride.Key,
+										limitOverride.OrderLimitId,
+										limitOverride.Amount,
+										limitOverride.ExpireAt,
+										limitOverride.CreateAt,
+										)
+
+	var id int
+
+	if err := row.Scan(&id); err != nil {
+		return nil, pgError(err)
+	}
+
+	limitOverride.ID = id
+
+	return &limitOverride, nil
+}
+
+// Above update an override
+func (w WorkerRepository) UpdateLimitOverride(ctx context.Context, limitOverride model.LimitOverride) (int64, error){
+	childLogger.Info().Str("func","UpdateLimitOverride").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.UpdateLimitOverride")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	//query
+	query := `update limit_override
+				set key = $2,
+					fk_order_limit_id = $3,
+					amount = $4,
+					expires_at = $5
+				where id = $1`
+
+	// execute
+	row, err := conn.Exec(ctx, query,	limitOverride.ID,
+										limitOverride.Key,
+										limitOverride.OrderLimitId,
+										limitOverride.Amount,
+										limitOverride.ExpireAt,
+										)
+	if err != nil {
+		return 0, pgError(err)
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// Above delete an override
+func (w WorkerRepository) DeleteLimitOverride(ctx context.Context, limitOverride model.LimitOverride) (int64, error){
+	childLogger.Info().Str("func","DeleteLimitOverride").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.DeleteLimitOverride")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	//query
+	query := `delete from limit_override where id = $1`
+
+	// execute
+	row, err := conn.Exec(ctx, query, limitOverride.ID)
+	if err != nil {
+		return 0, pgError(err)
+	}
+
+	return row.RowsAffected(), nil
+}
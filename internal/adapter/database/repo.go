@@ -7,11 +7,13 @@ import (
 	
 	"github.com/go-limit/internal/core/model"
 	"github.com/go-limit/internal/core/erro"
+	"github.com/go-limit/internal/core/port"
 
 	go_core_observ "github.com/eliezerraj/go-core/observability"
 	go_core_pg "github.com/eliezerraj/go-core/database/pg"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
 )
 
@@ -24,6 +26,9 @@ type WorkerRepository struct {
 	DatabasePGServer *go_core_pg.DatabasePGServer
 }
 
+// Above the postgres adapter of the storage port
+var _ port.WorkerRepository = (*WorkerRepository)(nil)
+
 // Above new worker
 func NewWorkerRepository(databasePGServer *go_core_pg.DatabasePGServer) *WorkerRepository{
 	childLogger.Info().Str("func","NewWorkerRepository").Send()
@@ -33,13 +38,52 @@ func NewWorkerRepository(databasePGServer *go_core_pg.DatabasePGServer) *WorkerR
 	}
 }
 
-// Above get stats from database
-func (w WorkerRepository) Stat(ctx context.Context) (go_core_pg.PoolStats){
+// Above a postgres transaction, the connection is released when it ends
+type PgTx struct {
+	tx					pgx.Tx
+	conn				*pgxpool.Conn
+	databasePGServer	*go_core_pg.DatabasePGServer
+}
+
+// Above commit and release the connection
+func (t *PgTx) Commit(ctx context.Context) error {
+	defer t.databasePGServer.ReleaseTx(t.conn)
+	return t.tx.Commit(ctx)
+}
+
+// Above rollback and release the connection
+func (t *PgTx) Rollback(ctx context.Context) error {
+	defer t.databasePGServer.ReleaseTx(t.conn)
+	return t.tx.Rollback(ctx)
+}
+
+// Above get the pgx transaction of a transaction opened by this repository
+func pgxTx(tx port.Tx) pgx.Tx {
+	return tx.(*PgTx).tx
+}
+
+// Above start a transaction
+func (w WorkerRepository) StartTx(ctx context.Context) (port.Tx, error){
+	childLogger.Info().Str("func","StartTx").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	tx, conn, err := w.DatabasePGServer.StartTx(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return &PgTx{	tx: tx,
+					conn: conn,
+					databasePGServer: w.DatabasePGServer,
+				}, nil
+}
+
+// Above get stats from database, converted to the stats of the core
+func (w WorkerRepository) Stat(ctx context.Context) (model.PoolStats){
 	childLogger.Info().Str("func","Stat").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
 	
 	stats := w.DatabasePGServer.Stat()
 
-	resPoolStats := go_core_pg.PoolStats{
+	resPoolStats := model.PoolStats{
 		AcquireCount:         stats.AcquireCount(),
 		AcquiredConns:        stats.AcquiredConns(),
 		CanceledAcquireCount: stats.CanceledAcquireCount(),
@@ -73,6 +117,8 @@ func (w WorkerRepository) GetTypeLimit(ctx context.Context, typeLimit model.Type
 
 	query := `select code,
 					 category,
+					 timezone,
+					 billing_cycle_day,
 					 created_at	
 			  from type_limit
 			  where code = $1`
@@ -89,6 +135,8 @@ func (w WorkerRepository) GetTypeLimit(ctx context.Context, typeLimit model.Type
 	for rows.Next() {
 		err := rows.Scan( 	&res_type_limit.Code,
 							&res_type_limit.Category, 
+							&res_type_limit.Timezone,
+							&res_type_limit.BillingCycleDay,
 							&res_type_limit.CreateAt,
 						)
 		if err != nil {
@@ -116,13 +164,20 @@ func (w WorkerRepository) GetOrderLimit(ctx context.Context, orderLimit model.Or
 	// prepare query
 	res_lis_order_limit := []model.OrderLimit{}
 
-	query := `select fk_type_limit_code,
+	query := `select id,
+					 fk_type_limit_code,
 					 fk_counter_limit_code,
 					 type,
-					 amount	
+					 amount,
+					 time_window,
+					 currency,
+					 mcc,
+					 conditions,
+					 expression
 			  from order_limit
 			  where fk_type_limit_code = $1
-			  and type = $2`
+			  and type = $2
+			  order by id`
 
 	rows, err := conn.Query(ctx, 
 							query, 
@@ -138,10 +193,16 @@ func (w WorkerRepository) GetOrderLimit(ctx context.Context, orderLimit model.Or
 			
 		res_order_limit := model.OrderLimit{}
 
-		err := rows.Scan( 	&res_order_limit.TypeLimit,
+		err := rows.Scan( 	&res_order_limit.ID,
+							&res_order_limit.TypeLimit,
 							&res_order_limit.CounterLimit, 
 							&res_order_limit.Type,
 							&res_order_limit.Amount,
+							&res_order_limit.Window,
+							&res_order_limit.Currency,
+							&res_order_limit.Mcc,
+							&res_order_limit.Conditions,
+							&res_order_limit.Expression,
 						)
 		if err != nil {
 			return nil, errors.New(err.Error())
@@ -153,40 +214,107 @@ func (w WorkerRepository) GetOrderLimit(ctx context.Context, orderLimit model.Or
 	return &res_lis_order_limit, nil
 }
 
-// Above get the transaction limit response
-func (w WorkerRepository) GetLimitTransactionPerKey(ctx context.Context, limit model.Limit) (*model.Limit, error){
-	childLogger.Info().Str("func","GetLimitTransactionPerKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+// Above lock a key until the end of the transaction
+// All the consumption of the same key are serialized, so the window sum read inside the transaction can not be stale
+func (w WorkerRepository) LockKey(ctx context.Context, tx port.Tx, key string) error{
+	childLogger.Info().Str("func","LockKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
 
 	// trace
-	span := tracerProvider.Span(ctx, "database.GetLimitTransactionPerKey")
+	span := tracerProvider.Span(ctx, "database.LockKey")
 	defer span.End()
 
-	// prepare database
-	conn, err := w.DatabasePGServer.Acquire(ctx)
+	query := `select pg_advisory_xact_lock(hashtextextended($1, 0))`
+
+	// execute
+	_, err := pgxTx(tx).Exec(ctx, query, key)
 	if err != nil {
-		return nil, errors.New(err.Error())
+		return errors.New(err.Error())
 	}
-	defer w.DatabasePGServer.Release(conn)
 
-	// prepare query
-	res_limit := model.Limit{}
+	return nil
+}
 
-	query := `select coalesce( sum(amount), 0) as transaction_sum_amount,
-					 coalesce( count(1), 0) as transaction_sum_count
-				from public.limit_transaction
-				where key = $1
-				and fk_type_limit_code = $2
-				and fk_order_limit_type = $3
-				and fk_counter_limit_code = $4
-				and created_at between (now() - interval '1 minute') and now()`
+// Above get the transaction limit response
+// The compensating entries of a reversal have a negative amount, so they net out the reversed consumption
+// The reservations still alive are counted, so the headroom held can not be spent twice
+// The settled consumption is read from the buckets entirely inside the window plus the rows of the edge of the window
+// Only the consumption of the scope is counted, the mccs (all of them without mccs), the rule and the window
+func (w WorkerRepository) GetLimitTransactionPerKey(ctx context.Context, tx port.Tx, limit model.Limit, scope model.LimitScope, windowStart time.Time) (*model.LimitUsage, error){
+	childLogger.Info().Str("func","GetLimitTransactionPerKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.GetLimitTransactionPerKey")
+	defer span.End()
+
+	// prepare query
+	res_limit_usage := model.LimitUsage{}
+
+	query := `with usage as (
+					select amount,
+						   quantity,
+						   first_created_at
+					from public.limit_counter_bucket
+					where key = $1
+					and fk_type_limit_code = $2
+					and fk_order_limit_type = $3
+					and fk_counter_limit_code = $4
+					and ($7::text[] is null or mcc = any($7))
+					and rule_id = $8
+					and time_window = $9
+					and bucket_start between $6 and now()
+					union all
+					select amount,
+						   1,
+						   created_at
+					from public.limit_transaction
+					where key = $1
+					and fk_type_limit_code = $2
+					and fk_order_limit_type = $3
+					and fk_counter_limit_code = $4
+					and status not like '%:BREACH'
+					and status not like '%:DECLINED'
+					and status not like '%:RESERVED'
+					and status not like '%:RELEASED'
+					and status not like '%:EXPIRED'
+					and ($7::text[] is null or mcc = any($7))
+					and rule_id = $8
+					and time_window = $9
+					and created_at >= $5
+					and created_at < $6
+					and created_at <= now()
+					union all
+					select amount,
+						   1,
+						   created_at
+					from public.limit_transaction
+					where key = $1
+					and fk_type_limit_code = $2
+					and fk_order_limit_type = $3
+					and fk_counter_limit_code = $4
+					and status like '%:RESERVED'
+					and ($7::text[] is null or mcc = any($7))
+					and rule_id = $8
+					and time_window = $9
+					and expires_at >= now()
+					and created_at between $5 and now()
+				)
+				select coalesce( sum(amount), 0) as transaction_sum_amount,
+					   coalesce( sum(quantity), 0) as transaction_sum_count,
+					   min(first_created_at) as transaction_first_created_at
+				from usage`
 
 	// execute			
-	rows, err := conn.Query(ctx, 
+	rows, err := pgxTx(tx).Query(ctx, 
 							query, 
 							limit.Key,
 							limit.TypeLimit,
 							limit.OrderLimit,
 							limit.CounterLimit,
+							windowStart,
+							bucketStart(windowStart),
+							scope.Mccs,
+							scope.RuleId,
+							scope.Window,
 						)
 	if err != nil {
 		return nil, errors.New(err.Error())
@@ -194,27 +322,30 @@ func (w WorkerRepository) GetLimitTransactionPerKey(ctx context.Context, limit m
 	defer rows.Close()
 
 	for rows.Next() {
-		err := rows.Scan( &res_limit.Amount,
-						  &res_limit.Quantity )
+		err := rows.Scan( &res_limit_usage.Amount,
+						  &res_limit_usage.Quantity,
+						  &res_limit_usage.FirstCreateAt )
 		if err != nil {
 			return nil, errors.New(err.Error())
         }
-		return &res_limit, nil
+		return &res_limit_usage, nil
 	}
 	
 	return nil, erro.ErrNotFound
 }
 
 // Above add transaction limit
-func (w WorkerRepository) AddLimitTransaction(ctx context.Context, tx pgx.Tx, limitTransaction model.LimitTransaction) (*model.LimitTransaction, error){
+func (w WorkerRepository) AddLimitTransaction(ctx context.Context, tx port.Tx, limitTransaction model.LimitTransaction) (*model.LimitTransaction, error){
 	childLogger.Info().Str("func","AddLimitTransaction").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
 
 	// trace
 	span := tracerProvider.Span(ctx, "database.AddLimitTransaction")
 	defer span.End()
 
-	// prepare
-	limitTransaction.CreareAt = time.Now()
+	// prepare, a compensating entry keeps the created_at of the original one
+	if limitTransaction.CreareAt.IsZero() {
+		limitTransaction.CreareAt = time.Now()
+	}
 
 	//query
 	query := `INSERT INTO limit_transaction (transaction_id,
@@ -224,11 +355,21 @@ func (w WorkerRepository) AddLimitTransaction(ctx context.Context, tx pgx.Tx, li
 											fk_order_limit_type,
 											status,
 											amount,
-											created_at) 
-											VALUES($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+											created_at,
+											fk_limit_transaction_id,
+											expires_at,
+											currency,
+											original_amount,
+											original_currency,
+											fx_rate,
+											adjustment_id,
+											mcc,
+											rule_id,
+											time_window) 
+											VALUES($1, $2, $3, $4, $5, $6, $7, $8, nullif($9, 0), $10, $11, nullif($12::numeric, 0), $13, nullif($14, '')::numeric, nullif($15, 0), $16, $17, $18) RETURNING id`
 
 	// execute
-	row := tx.QueryRow(ctx, query,  limitTransaction.TransactionId, 
+	row := pgxTx(tx).QueryRow(ctx, query,  limitTransaction.TransactionId, 
 									limitTransaction.Key,
 									limitTransaction.TypeLimit,
 									limitTransaction.CounterLimit,
@@ -236,6 +377,16 @@ func (w WorkerRepository) AddLimitTransaction(ctx context.Context, tx pgx.Tx, li
 									limitTransaction.Status,
 									limitTransaction.Amount,
 									limitTransaction.CreareAt,
+									limitTransaction.ReferenceId,
+									limitTransaction.ExpireAt,
+									limitTransaction.Currency,
+									limitTransaction.OriginalAmount,
+									limitTransaction.OriginalCurrency,
+									limitTransaction.FxRate,
+									limitTransaction.AdjustmentId,
+									limitTransaction.Mcc,
+									limitTransaction.RuleId,
+									limitTransaction.Window,
 									)
 
 	var id int
@@ -246,5 +397,264 @@ func (w WorkerRepository) AddLimitTransaction(ctx context.Context, tx pgx.Tx, li
 
 	limitTransaction.ID = id
 
+	// maintain the rolling counters
+	if isBucketStatus(limitTransaction.Status) {
+		err := w.addCounterBucket(ctx, tx, limitTransaction)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &limitTransaction, nil
 }
+
+// Above get a limit request already evaluated
+func (w WorkerRepository) GetLimitRequest(ctx context.Context, tx port.Tx, limitRequest model.LimitRequest) (*model.LimitRequest, error){
+	childLogger.Info().Str("func","GetLimitRequest").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.GetLimitRequest")
+	defer span.End()
+
+	// prepare query
+	res_limit_request := model.LimitRequest{}
+
+	query := `select transaction_id,
+					 payload_hash,
+					 response,
+					 created_at
+			  from limit_request
+			  where transaction_id = $1`
+
+	rows, err := pgxTx(tx).Query(ctx, 
+							query, 
+							limitRequest.TransactionId)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	// execute	
+	for rows.Next() {
+		err := rows.Scan( 	&res_limit_request.TransactionId,
+							&res_limit_request.PayloadHash, 
+							&res_limit_request.Response,
+							&res_limit_request.CreateAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		return &res_limit_request, nil
+	}
+	
+	return nil, erro.ErrNotFound
+}
+
+// Above add a limit request evaluated
+func (w WorkerRepository) AddLimitRequest(ctx context.Context, tx port.Tx, limitRequest model.LimitRequest) (*model.LimitRequest, error){
+	childLogger.Info().Str("func","AddLimitRequest").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddLimitRequest")
+	defer span.End()
+
+	// prepare
+	limitRequest.CreateAt = time.Now()
+
+	//query
+	query := `INSERT INTO limit_request (transaction_id,
+										payload_hash,
+										response,
+										created_at) 
+										VALUES($1, $2, $3, $4)`
+
+	// execute
+	_, err := pgxTx(tx).Exec(ctx, query,	limitRequest.TransactionId,
+									limitRequest.PayloadHash,
+									limitRequest.Response,
+									limitRequest.CreateAt,
+									)
+	if err != nil {
+		// the same transaction_id was evaluated concurrently
+		return nil, pgError(err)
+	}
+
+	return &limitRequest, nil
+}
+
+// Above get all limit transaction of a transaction_id, the rows are locked until the end of the transaction
+func (w WorkerRepository) GetLimitTransactionByTransactionId(ctx context.Context, tx port.Tx, limitTransaction model.LimitTransaction) (*[]model.LimitTransaction, error){
+	childLogger.Info().Str("func","GetLimitTransactionByTransactionId").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.GetLimitTransactionByTransactionId")
+	defer span.End()
+
+	// prepare query
+	res_list_limit_transaction := []model.LimitTransaction{}
+
+	query := `select id,
+					 transaction_id,
+					 key,
+					 fk_type_limit_code,
+					 fk_counter_limit_code,
+					 fk_order_limit_type,
+					 status,
+					 amount,
+					 reversed_amount,
+					 reversed_at,
+					 expires_at,
+					 created_at,
+					 currency,
+					 original_amount,
+					 original_currency,
+					 coalesce(fx_rate::text, ''),
+					 coalesce(adjustment_id, 0),
+					 mcc,
+					 rule_id,
+					 time_window
+			  from limit_transaction
+			  where transaction_id = $1
+			  and fk_limit_transaction_id is null
+			  order by id
+			  for update`
+
+	rows, err := pgxTx(tx).Query(ctx, 
+							query, 
+							limitTransaction.TransactionId)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	// execute	
+	for rows.Next() {
+		res_limit_transaction := model.LimitTransaction{}
+
+		err := rows.Scan( 	&res_limit_transaction.ID,
+							&res_limit_transaction.TransactionId,
+							&res_limit_transaction.Key,
+							&res_limit_transaction.TypeLimit,
+							&res_limit_transaction.CounterLimit,
+							&res_limit_transaction.OrderLimit,
+							&res_limit_transaction.Status,
+							&res_limit_transaction.Amount,
+							&res_limit_transaction.ReversedAmount,
+							&res_limit_transaction.ReversedAt,
+							&res_limit_transaction.ExpireAt,
+							&res_limit_transaction.CreareAt,
+							&res_limit_transaction.Currency,
+							&res_limit_transaction.OriginalAmount,
+							&res_limit_transaction.OriginalCurrency,
+							&res_limit_transaction.FxRate,
+							&res_limit_transaction.AdjustmentId,
+							&res_limit_transaction.Mcc,
+							&res_limit_transaction.RuleId,
+							&res_limit_transaction.Window,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+
+		res_list_limit_transaction = append(res_list_limit_transaction, res_limit_transaction)
+	}
+
+	if len(res_list_limit_transaction) == 0 {
+		return nil, erro.ErrNotFound
+	}
+	
+	return &res_list_limit_transaction, nil
+}
+
+// Above update the reversal of a limit transaction
+func (w WorkerRepository) UpdateLimitTransactionReversal(ctx context.Context, tx port.Tx, limitTransaction model.LimitTransaction) (int64, error){
+	childLogger.Info().Str("func","UpdateLimitTransactionReversal").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.UpdateLimitTransactionReversal")
+	defer span.End()
+
+	//query
+	query := `update limit_transaction
+				set status = $2,
+					reversed_amount = $3,
+					reversed_at = $4
+				where id = $1`
+
+	// execute
+	row, err := pgxTx(tx).Exec(ctx, query,	limitTransaction.ID,
+									limitTransaction.Status,
+									limitTransaction.ReversedAmount,
+									limitTransaction.ReversedAt,
+									)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// Above update the status of a limit transaction (confirm or release a reservation)
+// A reservation confirmed enters the buckets
+func (w WorkerRepository) UpdateLimitTransactionStatus(ctx context.Context, tx port.Tx, limitTransaction model.LimitTransaction) (int64, error){
+	childLogger.Info().Str("func","UpdateLimitTransactionStatus").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.UpdateLimitTransactionStatus")
+	defer span.End()
+
+	//query
+	query := `update limit_transaction
+				set status = $2,
+					expires_at = $3
+				where id = $1`
+
+	// execute
+	row, err := pgxTx(tx).Exec(ctx, query,	limitTransaction.ID,
+									limitTransaction.Status,
+									limitTransaction.ExpireAt,
+									)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	// maintain the rolling counters
+	if row.RowsAffected() > 0 && isBucketStatus(limitTransaction.Status) {
+		err = w.addCounterBucket(ctx, tx, limitTransaction)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// Above expire all reservations not confirmed or released in time
+func (w WorkerRepository) ExpireLimitReservation(ctx context.Context) (int64, error){
+	childLogger.Info().Str("func","ExpireLimitReservation").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.ExpireLimitReservation")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	//query
+	query := `update limit_transaction
+				set status = replace(status, ':RESERVED', ':EXPIRED')
+				where status like '%:RESERVED'
+				and expires_at < now()`
+
+	// execute
+	row, err := conn.Exec(ctx, query)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected(), nil
+}
\ No newline at end of file
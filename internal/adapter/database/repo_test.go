@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	go_core_pg "github.com/eliezerraj/go-core/database/pg"
+)
+
+// a repository over the postgres of DB_HOST, DB_PORT, DB_NAME, DB_USER and DB_PASSWORD
+// the test is skipped without a database
+func newTestRepository(t *testing.T) *WorkerRepository {
+	t.Helper()
+
+	if os.Getenv("DB_HOST") == "" {
+		t.Skip("DB_HOST not set, skipping the postgres test")
+	}
+
+	databaseConfig := go_core_pg.DatabaseConfig{	Host: os.Getenv("DB_HOST"),
+													Port: os.Getenv("DB_PORT"),
+													DatabaseName: os.Getenv("DB_NAME"),
+													User: os.Getenv("DB_USER"),
+													Password: os.Getenv("DB_PASSWORD"),
+													DbMax_Connection: 60,
+												}
+
+	var databasePGServer go_core_pg.DatabasePGServer
+	databasePGServer, err := databasePGServer.NewDatabasePGServer(context.Background(), databaseConfig)
+	if err != nil {
+		t.Fatalf("NewDatabasePGServer: %v", err)
+	}
+	t.Cleanup(databasePGServer.CloseConnection)
+
+	return NewWorkerRepository(&databasePGServer)
+}
+
+// a transaction that holds the lock of a key blocks the others of the same key until it ends, not another key
+func TestLockKey(t *testing.T) {
+	ctx := context.Background()
+	repository := newTestRepository(t)
+
+	tx, err := repository.StartTx(ctx)
+	if err != nil {
+		t.Fatalf("StartTx: %v", err)
+	}
+	if err := repository.LockKey(ctx, tx, "card-1"); err != nil {
+		t.Fatalf("LockKey: %v", err)
+	}
+
+	lockKey := func(key string, done chan error) {
+		tx, err := repository.StartTx(ctx)
+		if err != nil {
+			done <- err
+			return
+		}
+		defer tx.Rollback(ctx)
+		done <- repository.LockKey(ctx, tx, key)
+	}
+
+	other := make(chan error, 1)
+	go lockKey("card-2", other)
+	select {
+	case err := <-other:
+		if err != nil {
+			t.Fatalf("LockKey of another key: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the lock of card-1 blocks card-2")
+	}
+
+	same := make(chan error, 1)
+	go lockKey("card-1", same)
+	select {
+	case <-same:
+		t.Fatalf("card-1 locked by two transactions")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	select {
+	case err := <-same:
+		if err != nil {
+			t.Fatalf("LockKey after the commit: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("the lock of card-1 is not released by the commit")
+	}
+}
+
+// many checks of the same key at the same time read and write the consumption one after the other
+func TestLockKeyConcurrent(t *testing.T) {
+	ctx := context.Background()
+	repository := newTestRepository(t)
+
+	// the consumption is read, then written after a while, as a check does with the window sum
+	// only the lock of the key keeps the checks from losing a write
+	var consumed atomic.Int64
+	var wait sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+
+			tx, err := repository.StartTx(ctx)
+			if err != nil {
+				t.Errorf("StartTx: %v", err)
+				return
+			}
+
+			if err := repository.LockKey(ctx, tx, "card-1"); err != nil {
+				tx.Rollback(ctx)
+				t.Errorf("LockKey: %v", err)
+				return
+			}
+			read := consumed.Load()
+			time.Sleep(time.Millisecond)
+			consumed.Store(read + 10)
+
+			if err := tx.Commit(ctx); err != nil {
+				t.Errorf("Commit: %v", err)
+			}
+		}()
+	}
+	wait.Wait()
+
+	if consumed.Load() != 500 {
+		t.Errorf("consumed = %d, want 500", consumed.Load())
+	}
+}
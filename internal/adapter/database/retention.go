@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Above purge a batch of limit transactions created before a time, moving them to the archive when asked
+// The compensating entries go together with their original, so the foreign key is never violated
+func (w WorkerRepository) PurgeLimitTransaction(ctx context.Context, before time.Time, batchSize int, archive bool) (int64, error){
+	childLogger.Info().Str("func","PurgeLimitTransaction").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.PurgeLimitTransaction")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	//query
+	query := `with batch as (
+					select id
+					from limit_transaction
+					where created_at < $1
+					limit $2
+					for update skip locked
+				)
+				delete from limit_transaction
+				where id in (select id from batch)
+				or fk_limit_transaction_id in (select id from batch)`
+
+	if archive {
+		query = `with batch as (
+					select id
+					from limit_transaction
+					where created_at < $1
+					limit $2
+					for update skip locked
+				), purged as (
+					delete from limit_transaction
+					where id in (select id from batch)
+					or fk_limit_transaction_id in (select id from batch)
+					returning *
+				)
+				insert into limit_transaction_archive
+				select * from purged`
+	}
+
+	// execute
+	row, err := conn.Exec(ctx, query, before, batchSize)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// Above purge a batch of buckets started before a time
+func (w WorkerRepository) PurgeCounterBucket(ctx context.Context, before time.Time, batchSize int) (int64, error){
+	childLogger.Info().Str("func","PurgeCounterBucket").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.PurgeCounterBucket")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	//query
+	query := `delete from limit_counter_bucket
+				where ctid in (	select ctid
+								from limit_counter_bucket
+								where bucket_start < $1
+								limit $2)`
+
+	// execute
+	row, err := conn.Exec(ctx, query, before, batchSize)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// Above purge a batch of limit requests evaluated before a time
+func (w WorkerRepository) PurgeLimitRequest(ctx context.Context, before time.Time, batchSize int) (int64, error){
+	childLogger.Info().Str("func","PurgeLimitRequest").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.PurgeLimitRequest")
+	defer span.End()
+
+	// prepare database
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	//query
+	query := `delete from limit_request
+				where transaction_id in (	select transaction_id
+											from limit_request
+											where created_at < $1
+											limit $2)`
+
+	// execute
+	row, err := conn.Exec(ctx, query, before, batchSize)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected(), nil
+}
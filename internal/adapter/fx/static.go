@@ -0,0 +1,74 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"math/big"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/erro"
+	"github.com/go-limit/internal/core/port"
+
+	"github.com/rs/zerolog/log"
+)
+
+var childLogger = log.With().Str("component","go-limit").Str("package","internal.adapter.fx").Logger()
+
+// Above the number of fractional digits of a rate derived (the inverse of a rate configured)
+const rateScale = 10
+
+// Above a fx provider with fixed rates (from the configuration)
+// The inverse of a rate configured is derived, so USD/BRL also gives BRL/USD
+type StaticFxProvider struct {
+	rates	map[string]*big.Rat
+}
+
+// Above the static adapter of the fx provider port
+var _ port.FxProvider = (*StaticFxProvider)(nil)
+
+// Above new static fx provider, the rates are a list as USD/BRL=5.4321;EUR/BRL=6.01
+func NewStaticFxProvider(fxRates string) (*StaticFxProvider, error){
+	childLogger.Info().Str("func","NewStaticFxProvider").Send()
+
+	p := &StaticFxProvider{
+		rates: map[string]*big.Rat{},
+	}
+
+	for _, item := range strings.Split(fxRates, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		pair, value, ok := strings.Cut(item, "=")
+		from, to, ok_pair := strings.Cut(strings.ToUpper(strings.TrimSpace(pair)), "/")
+		rate, ok_rate := new(big.Rat).SetString(strings.TrimSpace(value))
+		if !ok || !ok_pair || !ok_rate || rate.Sign() <= 0 {
+			return nil, errors.New("invalid fx rate " + item)
+		}
+
+		p.rates[from + "/" + to] = rate
+		if _, exists := p.rates[to + "/" + from]; !exists {
+			p.rates[to + "/" + from] = new(big.Rat).Inv(rate)
+		}
+	}
+
+	return p, nil
+}
+
+// Above get the rate of a pair of currencies
+func (p *StaticFxProvider) GetFxRate(ctx context.Context, fxRate model.FxRate) (*model.FxRate, error){
+	childLogger.Info().Str("func","GetFxRate").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	rate, ok := p.rates[fxRate.From + "/" + fxRate.To]
+	if !ok {
+		return nil, erro.ErrFxRate
+	}
+
+	// an exact rate keeps all its digits, a derived one is limited to the scale
+	fxRate.Rate = strings.TrimRight(strings.TrimRight(rate.FloatString(rateScale), "0"), ".")
+	fxRate.Source = "static"
+
+	return &fxRate, nil
+}
@@ -0,0 +1,909 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/erro"
+	"github.com/go-limit/internal/core/port"
+
+	"github.com/rs/zerolog/log"
+)
+
+var childLogger = log.With().Str("component","go-limit").Str("package","internal.adapter.memory").Logger()
+
+// Above the counters created by the schema migration
+var defaultCounterLimit = []string{"VALUE", "QUANTITY", "SECOND", "MINUTE", "HOUR"}
+
+// Above an in-memory storage, for local development and tests, nothing survives a restart
+// The transactions are serialized (one at a time), writes inside a transaction are undone on rollback
+type WorkerRepository struct {
+	mutex				sync.Mutex
+	txSemaphore			chan struct{}
+	typeLimit			map[string]model.TypeLimit
+	counterLimit		map[string]model.CounterLimit
+	orderLimit			map[int]model.OrderLimit
+	orderLimitSeq		int
+	mccGroup			map[string]model.MccGroup
+	limitOverride		map[int]model.LimitOverride
+	limitOverrideSeq	int
+	limitAdjustment		map[int]model.LimitAdjustment
+	limitAdjustmentSeq	int
+	limitTransaction	[]model.LimitTransaction
+	limitTransactionOffset	int
+	limitRequest		map[string]model.LimitRequest
+}
+
+// Above the in-memory adapter of the storage port
+var _ port.WorkerRepository = (*WorkerRepository)(nil)
+
+// Above new worker
+func NewWorkerRepository() *WorkerRepository{
+	childLogger.Info().Str("func","NewWorkerRepository").Send()
+
+	w := &WorkerRepository{
+		txSemaphore: make(chan struct{}, 1),
+		typeLimit: map[string]model.TypeLimit{},
+		counterLimit: map[string]model.CounterLimit{},
+		orderLimit: map[int]model.OrderLimit{},
+		mccGroup: map[string]model.MccGroup{},
+		limitOverride: map[int]model.LimitOverride{},
+		limitAdjustment: map[int]model.LimitAdjustment{},
+		limitRequest: map[string]model.LimitRequest{},
+	}
+	for _, code := range defaultCounterLimit {
+		w.counterLimit[code] = model.CounterLimit{Code: code}
+	}
+
+	return w
+}
+
+// Above an in-memory transaction
+type MemoryTx struct {
+	repository	*WorkerRepository
+	undo		[]func()
+	done		bool
+}
+
+// Above keep the writes and let the next transaction start
+func (t *MemoryTx) Commit(ctx context.Context) error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	t.undo = nil
+	<-t.repository.txSemaphore
+
+	return nil
+}
+
+// Above undo the writes (newest first) and let the next transaction start
+func (t *MemoryTx) Rollback(ctx context.Context) error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+
+	t.repository.mutex.Lock()
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		t.undo[i]()
+	}
+	t.repository.mutex.Unlock()
+	t.undo = nil
+	<-t.repository.txSemaphore
+
+	return nil
+}
+
+// Above get the in-memory transaction
+func memoryTx(tx port.Tx) *MemoryTx {
+	return tx.(*MemoryTx)
+}
+
+// Above there is no pool, the stats are empty
+func (w *WorkerRepository) Stat(ctx context.Context) (model.PoolStats){
+	childLogger.Info().Str("func","Stat").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	return model.PoolStats{}
+}
+
+// Above start a transaction, waiting while another one is running
+func (w *WorkerRepository) StartTx(ctx context.Context) (port.Tx, error){
+	childLogger.Info().Str("func","StartTx").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	select {
+	case w.txSemaphore <- struct{}{}:
+		return &MemoryTx{repository: w}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Above the transactions are already serialized, so the key is locked by the transaction itself
+func (w *WorkerRepository) LockKey(ctx context.Context, tx port.Tx, key string) error{
+	return nil
+}
+
+// Above get type limit
+func (w *WorkerRepository) GetTypeLimit(ctx context.Context, typeLimit model.TypeLimit) (*model.TypeLimit, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	res_type_limit, ok := w.typeLimit[typeLimit.Code]
+	if !ok {
+		return nil, erro.ErrNotFound
+	}
+
+	return &res_type_limit, nil
+}
+
+// Above list all type limit
+func (w *WorkerRepository) ListTypeLimit(ctx context.Context) (*[]model.TypeLimit, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	res_list_type_limit := []model.TypeLimit{}
+	for _, val := range w.typeLimit {
+		res_list_type_limit = append(res_list_type_limit, val)
+	}
+	sort.Slice(res_list_type_limit, func(i, j int) bool { return res_list_type_limit[i].Code < res_list_type_limit[j].Code })
+
+	return &res_list_type_limit, nil
+}
+
+// Above a type limit without timezone is in UTC and its billing cycle starts on the first day (the defaults of the table)
+func defaultTypeLimit(typeLimit model.TypeLimit) model.TypeLimit {
+	if typeLimit.Timezone == "" {
+		typeLimit.Timezone = "UTC"
+	}
+	if typeLimit.BillingCycleDay == 0 {
+		typeLimit.BillingCycleDay = 1
+	}
+	return typeLimit
+}
+
+// Above add a type limit
+func (w *WorkerRepository) AddTypeLimit(ctx context.Context, typeLimit model.TypeLimit) (*model.TypeLimit, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if _, ok := w.typeLimit[typeLimit.Code]; ok {
+		return nil, erro.ErrConflict
+	}
+
+	typeLimit = defaultTypeLimit(typeLimit)
+	typeLimit.CreateAt = time.Now()
+	w.typeLimit[typeLimit.Code] = typeLimit
+
+	return &typeLimit, nil
+}
+
+// Above update a type limit
+func (w *WorkerRepository) UpdateTypeLimit(ctx context.Context, typeLimit model.TypeLimit) (int64, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	res_type_limit, ok := w.typeLimit[typeLimit.Code]
+	if !ok {
+		return 0, nil
+	}
+
+	typeLimit = defaultTypeLimit(typeLimit)
+	res_type_limit.Category = typeLimit.Category
+	res_type_limit.Timezone = typeLimit.Timezone
+	res_type_limit.BillingCycleDay = typeLimit.BillingCycleDay
+	w.typeLimit[typeLimit.Code] = res_type_limit
+
+	return 1, nil
+}
+
+// Above delete a type limit, it is a conflict while there are order limits using it
+func (w *WorkerRepository) DeleteTypeLimit(ctx context.Context, typeLimit model.TypeLimit) (int64, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if _, ok := w.typeLimit[typeLimit.Code]; !ok {
+		return 0, nil
+	}
+	for _, val := range w.orderLimit {
+		if val.TypeLimit == typeLimit.Code {
+			return 0, erro.ErrConflict
+		}
+	}
+
+	delete(w.typeLimit, typeLimit.Code)
+
+	return 1, nil
+}
+
+// Above get a counter limit
+func (w *WorkerRepository) GetCounterLimit(ctx context.Context, counterLimit model.CounterLimit) (*model.CounterLimit, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	res_counter_limit, ok := w.counterLimit[counterLimit.Code]
+	if !ok {
+		return nil, erro.ErrNotFound
+	}
+
+	return &res_counter_limit, nil
+}
+
+// Above get the order limits of a type limit and type
+func (w *WorkerRepository) GetOrderLimit(ctx context.Context, orderLimit model.OrderLimit) (*[]model.OrderLimit, error){
+	return w.listOrderLimit(func(val model.OrderLimit) bool {
+		return val.TypeLimit == orderLimit.TypeLimit && val.Type == orderLimit.CounterLimit
+	}), nil
+}
+
+// Above get an order limit by id
+func (w *WorkerRepository) GetOrderLimitById(ctx context.Context, orderLimit model.OrderLimit) (*model.OrderLimit, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	res_order_limit, ok := w.orderLimit[orderLimit.ID]
+	if !ok {
+		return nil, erro.ErrNotFound
+	}
+
+	return &res_order_limit, nil
+}
+
+// Above list the order limits, all of them or only the ones of a type limit
+func (w *WorkerRepository) ListOrderLimit(ctx context.Context, orderLimit model.OrderLimit) (*[]model.OrderLimit, error){
+	return w.listOrderLimit(func(val model.OrderLimit) bool {
+		return orderLimit.TypeLimit == "" || val.TypeLimit == orderLimit.TypeLimit
+	}), nil
+}
+
+// Above list the order limits matching a filter, ordered by id
+func (w *WorkerRepository) listOrderLimit(filter func(model.OrderLimit) bool) *[]model.OrderLimit {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	res_list_order_limit := []model.OrderLimit{}
+	for _, val := range w.orderLimit {
+		if filter(val) {
+			res_list_order_limit = append(res_list_order_limit, val)
+		}
+	}
+	sort.Slice(res_list_order_limit, func(i, j int) bool { return res_list_order_limit[i].ID < res_list_order_limit[j].ID })
+
+	return &res_list_order_limit
+}
+
+// Above check if two order limits have the same conditions (in the same order, as the jsonb compared by the table)
+func sameConditions(conditions []model.LimitCondition, other []model.LimitCondition) bool {
+	if len(conditions) != len(other) {
+		return false
+	}
+	for i, val := range conditions {
+		if val.Attribute != other[i].Attribute || val.Operator != other[i].Operator || len(val.Values) != len(other[i].Values) {
+			return false
+		}
+		for j, value := range val.Values {
+			if value != other[i].Values[j] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Above check the references and the uniqueness of an order limit (the constraints of the table)
+func (w *WorkerRepository) checkOrderLimit(orderLimit model.OrderLimit) error {
+	if _, ok := w.typeLimit[orderLimit.TypeLimit]; !ok {
+		return erro.ErrConflict
+	}
+	if _, ok := w.counterLimit[orderLimit.CounterLimit]; !ok {
+		return erro.ErrConflict
+	}
+	for _, val := range w.orderLimit {
+		if val.ID != orderLimit.ID &&
+			val.TypeLimit == orderLimit.TypeLimit &&
+			val.Type == orderLimit.Type &&
+			val.CounterLimit == orderLimit.CounterLimit &&
+			val.Mcc == orderLimit.Mcc &&
+			sameConditions(val.Conditions, orderLimit.Conditions) &&
+			val.Expression == orderLimit.Expression &&
+			val.Window == orderLimit.Window {
+			return erro.ErrConflict
+		}
+	}
+	return nil
+}
+
+// Above add an order limit
+func (w *WorkerRepository) AddOrderLimit(ctx context.Context, orderLimit model.OrderLimit) (*model.OrderLimit, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	orderLimit.ID = 0
+	if err := w.checkOrderLimit(orderLimit); err != nil {
+		return nil, err
+	}
+
+	w.orderLimitSeq = w.orderLimitSeq + 1
+	orderLimit.ID = w.orderLimitSeq
+	orderLimit.CreateAt = time.Now()
+	w.orderLimit[orderLimit.ID] = orderLimit
+
+	return &orderLimit, nil
+}
+
+// Above update an order limit
+func (w *WorkerRepository) UpdateOrderLimit(ctx context.Context, orderLimit model.OrderLimit) (int64, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	res_order_limit, ok := w.orderLimit[orderLimit.ID]
+	if !ok {
+		return 0, nil
+	}
+	if err := w.checkOrderLimit(orderLimit); err != nil {
+		return 0, err
+	}
+
+	orderLimit.CreateAt = res_order_limit.CreateAt
+	w.orderLimit[orderLimit.ID] = orderLimit
+
+	return 1, nil
+}
+
+// Above delete an order limit
+func (w *WorkerRepository) DeleteOrderLimit(ctx context.Context, orderLimit model.OrderLimit) (int64, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if _, ok := w.orderLimit[orderLimit.ID]; !ok {
+		return 0, nil
+	}
+	delete(w.orderLimit, orderLimit.ID)
+
+	// the overrides and adjustments of the order limit are deleted with it (on delete cascade)
+	for id, val := range w.limitOverride {
+		if val.OrderLimitId == orderLimit.ID {
+			delete(w.limitOverride, id)
+		}
+	}
+	for id, val := range w.limitAdjustment {
+		if val.OrderLimitId == orderLimit.ID {
+			delete(w.limitAdjustment, id)
+		}
+	}
+
+	return 1, nil
+}
+
+// Above get a mcc group
+func (w *WorkerRepository) GetMccGroup(ctx context.Context, mccGroup model.MccGroup) (*model.MccGroup, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	res_mcc_group, ok := w.mccGroup[mccGroup.Code]
+	if !ok {
+		return nil, erro.ErrNotFound
+	}
+
+	return &res_mcc_group, nil
+}
+
+// Above list all mcc groups
+func (w *WorkerRepository) ListMccGroup(ctx context.Context) (*[]model.MccGroup, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	res_list_mcc_group := []model.MccGroup{}
+	for _, val := range w.mccGroup {
+		res_list_mcc_group = append(res_list_mcc_group, val)
+	}
+	sort.Slice(res_list_mcc_group, func(i, j int) bool { return res_list_mcc_group[i].Code < res_list_mcc_group[j].Code })
+
+	return &res_list_mcc_group, nil
+}
+
+// Above add a mcc group
+func (w *WorkerRepository) AddMccGroup(ctx context.Context, mccGroup model.MccGroup) (*model.MccGroup, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if _, ok := w.mccGroup[mccGroup.Code]; ok {
+		return nil, erro.ErrConflict
+	}
+
+	mccGroup.CreateAt = time.Now()
+	w.mccGroup[mccGroup.Code] = mccGroup
+
+	return &mccGroup, nil
+}
+
+// Above update the mccs of a mcc group
+func (w *WorkerRepository) UpdateMccGroup(ctx context.Context, mccGroup model.MccGroup) (int64, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	res_mcc_group, ok := w.mccGroup[mccGroup.Code]
+	if !ok {
+		return 0, nil
+	}
+
+	res_mcc_group.Mccs = mccGroup.Mccs
+	w.mccGroup[mccGroup.Code] = res_mcc_group
+
+	return 1, nil
+}
+
+// Above delete a mcc group
+func (w *WorkerRepository) DeleteMccGroup(ctx context.Context, mccGroup model.MccGroup) (int64, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if _, ok := w.mccGroup[mccGroup.Code]; !ok {
+		return 0, nil
+	}
+	delete(w.mccGroup, mccGroup.Code)
+
+	return 1, nil
+}
+
+// Above get the overrides of a key still active (not expired)
+func (w *WorkerRepository) GetLimitOverride(ctx context.Context, limitOverride model.LimitOverride) (*[]model.LimitOverride, error){
+	now := time.Now()
+	return w.listLimitOverride(func(val model.LimitOverride) bool {
+		return val.Key == limitOverride.Key && (val.ExpireAt == nil || val.ExpireAt.After(now))
+	}), nil
+}
+
+// Above get an override by id
+func (w *WorkerRepository) GetLimitOverrideById(ctx context.Context, limitOverride model.LimitOverride) (*model.LimitOverride, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	res_limit_override, ok := w.limitOverride[limitOverride.ID]
+	if !ok {
+		return nil, erro.ErrNotFound
+	}
+
+	return &res_limit_override, nil
+}
+
+// Above list the overrides (expired included), all of them or only the ones of a key
+func (w *WorkerRepository) ListLimitOverride(ctx context.Context, limitOverride model.LimitOverride) (*[]model.LimitOverride, error){
+	return w.listLimitOverride(func(val model.LimitOverride) bool {
+		return limitOverride.Key == "" || val.Key == limitOverride.Key
+	}), nil
+}
+
+// Above list the overrides matching a filter, ordered by id
+func (w *WorkerRepository) listLimitOverride(filter func(model.LimitOverride) bool) *[]model.LimitOverride {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	res_list_limit_override := []model.LimitOverride{}
+	for _, val := range w.limitOverride {
+		if filter(val) {
+			res_list_limit_override = append(res_list_limit_override, val)
+		}
+	}
+	sort.Slice(res_list_limit_override, func(i, j int) bool { return res_list_limit_override[i].ID < res_list_limit_override[j].ID })
+
+	return &res_list_limit_override
+}
+
+// Above check the reference and the uniqueness of an override (the constraints of the table)
+func (w *WorkerRepository) checkLimitOverride(limitOverride model.LimitOverride) error {
+	if _, ok := w.orderLimit[limitOverride.OrderLimitId]; !ok {
+		return erro.ErrConflict
+	}
+	for _, val := range w.limitOverride {
+		if val.ID != limitOverride.ID &&
+			val.Key == limitOverride.Key &&
+			val.OrderLimitId == limitOverride.OrderLimitId {
+			return erro.ErrConflict
+		}
+	}
+	return nil
+}
+
+// Above add an override
+func (w *WorkerRepository) AddLimitOverride(ctx context.Context, limitOverride model.LimitOverride) (*model.LimitOverride, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	limitOverride.ID = 0
+	if err := w.checkLimitOverride(limitOverride); err != nil {
+		return nil, err
+	}
+
+	w.limitOverrideSeq = w.limitOverrideSeq + 1
+	limitOverride.ID = w.limitOverrideSeq
+	limitOverride.CreateAt = time.Now()
+	w.limitOverride[limitOverride.ID] = limitOverride
+
+	return &limitOverride, nil
+}
+
+// Above update an override
+func (w *WorkerRepository) UpdateLimitOverride(ctx context.Context, limitOverride model.LimitOverride) (int64, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	res_limit_override, ok := w.limitOverride[limitOverride.ID]
+	if !ok {
+		return 0, nil
+	}
+	if err := w.checkLimitOverride(limitOverride); err != nil {
+		return 0, err
+	}
+
+	limitOverride.CreateAt = res_limit_override.CreateAt
+	w.limitOverride[limitOverride.ID] = limitOverride
+
+	return 1, nil
+}
+
+// Above delete an override
+func (w *WorkerRepository) DeleteLimitOverride(ctx context.Context, limitOverride model.LimitOverride) (int64, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if _, ok := w.limitOverride[limitOverride.ID]; !ok {
+		return 0, nil
+	}
+	delete(w.limitOverride, limitOverride.ID)
+
+	return 1, nil
+}
+
+// Above get the adjustments of a key active now
+func (w *WorkerRepository) GetLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) (*[]model.LimitAdjustment, error){
+	now := time.Now()
+	return w.listLimitAdjustment(func(val model.LimitAdjustment) bool {
+		return val.Key == limitAdjustment.Key && !val.StartAt.After(now) && val.EndAt.After(now)
+	}), nil
+}
+
+// Above get an adjustment by id
+func (w *WorkerRepository) GetLimitAdjustmentById(ctx context.Context, limitAdjustment model.LimitAdjustment) (*model.LimitAdjustment, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	res_limit_adjustment, ok := w.limitAdjustment[limitAdjustment.ID]
+	if !ok {
+		return nil, erro.ErrNotFound
+	}
+
+	return &res_limit_adjustment, nil
+}
+
+// Above list the adjustments (past and scheduled included), all of them or only the ones of a key
+func (w *WorkerRepository) ListLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) (*[]model.LimitAdjustment, error){
+	return w.listLimitAdjustment(func(val model.LimitAdjustment) bool {
+		return limitAdjustment.Key == "" || val.Key == limitAdjustment.Key
+	}), nil
+}
+
+// Above list the adjustments matching a filter, ordered by id
+func (w *WorkerRepository) listLimitAdjustment(filter func(model.LimitAdjustment) bool) *[]model.LimitAdjustment {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	res_list_limit_adjustment := []model.LimitAdjustment{}
+	for _, val := range w.limitAdjustment {
+		if filter(val) {
+			res_list_limit_adjustment = append(res_list_limit_adjustment, val)
+		}
+	}
+	sort.Slice(res_list_limit_adjustment, func(i, j int) bool { return res_list_limit_adjustment[i].ID < res_list_limit_adjustment[j].ID })
+
+	return &res_list_limit_adjustment
+}
+
+// Above check the reference of an adjustment (the constraint of the table)
+func (w *WorkerRepository) checkLimitAdjustment(limitAdjustment model.LimitAdjustment) error {
+	if _, ok := w.orderLimit[limitAdjustment.OrderLimitId]; !ok {
+		return erro.ErrConflict
+	}
+	return nil
+}
+
+// Above add an adjustment
+func (w *WorkerRepository) AddLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) (*model.LimitAdjustment, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	limitAdjustment.ID = 0
+	if err := w.checkLimitAdjustment(limitAdjustment); err != nil {
+		return nil, err
+	}
+
+	w.limitAdjustmentSeq = w.limitAdjustmentSeq + 1
+	limitAdjustment.ID = w.limitAdjustmentSeq
+	limitAdjustment.CreateAt = time.Now()
+	w.limitAdjustment[limitAdjustment.ID] = limitAdjustment
+
+	return &limitAdjustment, nil
+}
+
+// Above update an adjustment
+func (w *WorkerRepository) UpdateLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) (int64, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	res_limit_adjustment, ok := w.limitAdjustment[limitAdjustment.ID]
+	if !ok {
+		return 0, nil
+	}
+	if err := w.checkLimitAdjustment(limitAdjustment); err != nil {
+		return 0, err
+	}
+
+	limitAdjustment.CreateAt = res_limit_adjustment.CreateAt
+	w.limitAdjustment[limitAdjustment.ID] = limitAdjustment
+
+	return 1, nil
+}
+
+// Above delete an adjustment
+func (w *WorkerRepository) DeleteLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) (int64, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if _, ok := w.limitAdjustment[limitAdjustment.ID]; !ok {
+		return 0, nil
+	}
+	delete(w.limitAdjustment, limitAdjustment.ID)
+
+	return 1, nil
+}
+
+// Above check if a limit transaction consumes the window (same rules of the postgres query)
+func isConsuming(limitTransaction model.LimitTransaction, now time.Time) bool {
+	status := limitTransaction.Status
+
+	if strings.HasSuffix(status, ":BREACH") ||
+		strings.HasSuffix(status, ":DECLINED") ||
+		strings.HasSuffix(status, ":RELEASED") ||
+		strings.HasSuffix(status, ":EXPIRED") {
+		return false
+	}
+	if strings.HasSuffix(status, ":RESERVED") && limitTransaction.ExpireAt != nil && limitTransaction.ExpireAt.Before(now) {
+		return false
+	}
+	return true
+}
+
+// Above check if the mcc of a limit transaction is one of the mccs, all of them match without mccs
+func isMccOf(mcc string, mccs []string) bool {
+	if mccs == nil {
+		return true
+	}
+	for _, val := range mccs {
+		if val == mcc {
+			return true
+		}
+	}
+	return false
+}
+
+// Above get the transaction limit response inside the window, only the consumption of the scope
+func (w *WorkerRepository) GetLimitTransactionPerKey(ctx context.Context, tx port.Tx, limit model.Limit, scope model.LimitScope, windowStart time.Time) (*model.LimitUsage, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	now := time.Now()
+	res_limit_usage := model.LimitUsage{}
+
+	for _, val := range w.limitTransaction {
+		if val.Key != limit.Key ||
+			val.TypeLimit != limit.TypeLimit ||
+			val.OrderLimit != limit.OrderLimit ||
+			val.CounterLimit != limit.CounterLimit ||
+			val.RuleId != scope.RuleId ||
+			val.Window != scope.Window ||
+			!isMccOf(val.Mcc, scope.Mccs) {
+			continue
+		}
+		if val.CreareAt.Before(windowStart) || val.CreareAt.After(now) || !isConsuming(val, now) {
+			continue
+		}
+
+		res_limit_usage.Amount = res_limit_usage.Amount + val.Amount
+		res_limit_usage.Quantity = res_limit_usage.Quantity + 1
+		if res_limit_usage.FirstCreateAt == nil || val.CreareAt.Before(*res_limit_usage.FirstCreateAt) {
+			first_create_at := val.CreareAt
+			res_limit_usage.FirstCreateAt = &first_create_at
+		}
+	}
+
+	return &res_limit_usage, nil
+}
+
+// Above add transaction limit
+func (w *WorkerRepository) AddLimitTransaction(ctx context.Context, tx port.Tx, limitTransaction model.LimitTransaction) (*model.LimitTransaction, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if limitTransaction.CreareAt.IsZero() {
+		limitTransaction.CreareAt = time.Now()
+	}
+	limitTransaction.ID = w.limitTransactionOffset + len(w.limitTransaction) + 1
+	w.limitTransaction = append(w.limitTransaction, limitTransaction)
+
+	// the transactions are serialized, so the row added is still the last one on rollback
+	t := memoryTx(tx)
+	t.undo = append(t.undo, func() {
+		w.limitTransaction = w.limitTransaction[:limitTransaction.ID - 1 - w.limitTransactionOffset]
+	})
+
+	return &limitTransaction, nil
+}
+
+// Above get all limit transaction of a transaction_id (not the compensating entries)
+func (w *WorkerRepository) GetLimitTransactionByTransactionId(ctx context.Context, tx port.Tx, limitTransaction model.LimitTransaction) (*[]model.LimitTransaction, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	res_list_limit_transaction := []model.LimitTransaction{}
+	for _, val := range w.limitTransaction {
+		if val.TransactionId == limitTransaction.TransactionId && val.ReferenceId == 0 {
+			res_list_limit_transaction = append(res_list_limit_transaction, val)
+		}
+	}
+
+	if len(res_list_limit_transaction) == 0 {
+		return nil, erro.ErrNotFound
+	}
+
+	return &res_list_limit_transaction, nil
+}
+
+// Above replace a limit transaction, keeping the previous one to undo
+func (w *WorkerRepository) updateLimitTransaction(tx port.Tx, limitTransaction model.LimitTransaction, update func(*model.LimitTransaction)) int64 {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	index := limitTransaction.ID - 1 - w.limitTransactionOffset
+	if index < 0 || index >= len(w.limitTransaction) {
+		return 0
+	}
+
+	previous := w.limitTransaction[index]
+	update(&w.limitTransaction[index])
+
+	t := memoryTx(tx)
+	t.undo = append(t.undo, func() {
+		w.limitTransaction[index] = previous
+	})
+
+	return 1
+}
+
+// Above update the reversal of a limit transaction
+func (w *WorkerRepository) UpdateLimitTransactionReversal(ctx context.Context, tx port.Tx, limitTransaction model.LimitTransaction) (int64, error){
+	return w.updateLimitTransaction(tx, limitTransaction, func(val *model.LimitTransaction) {
+		val.Status = limitTransaction.Status
+		val.ReversedAmount = limitTransaction.ReversedAmount
+		val.ReversedAt = limitTransaction.ReversedAt
+	}), nil
+}
+
+// Above update the status of a limit transaction (confirm or release a reservation)
+func (w *WorkerRepository) UpdateLimitTransactionStatus(ctx context.Context, tx port.Tx, limitTransaction model.LimitTransaction) (int64, error){
+	return w.updateLimitTransaction(tx, limitTransaction, func(val *model.LimitTransaction) {
+		val.Status = limitTransaction.Status
+		val.ExpireAt = limitTransaction.ExpireAt
+	}), nil
+}
+
+// Above expire all reservations not confirmed or released in time
+func (w *WorkerRepository) ExpireLimitReservation(ctx context.Context) (int64, error){
+	tx, err := w.StartTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Commit(ctx)
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	now := time.Now()
+	var res int64
+	for i, val := range w.limitTransaction {
+		if strings.HasSuffix(val.Status, ":RESERVED") && val.ExpireAt != nil && val.ExpireAt.Before(now) {
+			w.limitTransaction[i].Status = strings.TrimSuffix(val.Status, ":RESERVED") + ":EXPIRED"
+			res = res + 1
+		}
+	}
+
+	return res, nil
+}
+
+// Above purge the limit transactions created before a time (there is no archive)
+// The rows are kept in the order of the id, so only the oldest prefix is purged
+func (w *WorkerRepository) PurgeLimitTransaction(ctx context.Context, before time.Time, batchSize int, archive bool) (int64, error){
+	tx, err := w.StartTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Commit(ctx)
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	var res int
+	for res < len(w.limitTransaction) && res < batchSize && w.limitTransaction[res].CreareAt.Before(before) {
+		res = res + 1
+	}
+	w.limitTransaction = w.limitTransaction[res:]
+	w.limitTransactionOffset = w.limitTransactionOffset + res
+
+	return int64(res), nil
+}
+
+// Above there are no buckets, the usage is always calculated from the limit transactions
+func (w *WorkerRepository) PurgeCounterBucket(ctx context.Context, before time.Time, batchSize int) (int64, error){
+	return 0, nil
+}
+
+// Above get a limit request already evaluated
+func (w *WorkerRepository) GetLimitRequest(ctx context.Context, tx port.Tx, limitRequest model.LimitRequest) (*model.LimitRequest, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	res_limit_request, ok := w.limitRequest[limitRequest.TransactionId]
+	if !ok {
+		return nil, erro.ErrNotFound
+	}
+
+	return &res_limit_request, nil
+}
+
+// Above add a limit request evaluated
+func (w *WorkerRepository) AddLimitRequest(ctx context.Context, tx port.Tx, limitRequest model.LimitRequest) (*model.LimitRequest, error){
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if _, ok := w.limitRequest[limitRequest.TransactionId]; ok {
+		return nil, erro.ErrConflict
+	}
+
+	limitRequest.CreateAt = time.Now()
+	w.limitRequest[limitRequest.TransactionId] = limitRequest
+
+	t := memoryTx(tx)
+	t.undo = append(t.undo, func() {
+		delete(w.limitRequest, limitRequest.TransactionId)
+	})
+
+	return &limitRequest, nil
+}
+
+// Above purge the limit requests evaluated before a time
+func (w *WorkerRepository) PurgeLimitRequest(ctx context.Context, before time.Time, batchSize int) (int64, error){
+	tx, err := w.StartTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Commit(ctx)
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	var res int
+	for transactionId, limitRequest := range w.limitRequest {
+		if res >= batchSize {
+			break
+		}
+		if limitRequest.CreateAt.Before(before) {
+			delete(w.limitRequest, transactionId)
+			res = res + 1
+		}
+	}
+
+	return int64(res), nil
+}
@@ -0,0 +1,590 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"encoding/json"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/erro"
+	"github.com/go-limit/internal/core/port"
+
+	go_core_observ "github.com/eliezerraj/go-core/observability"
+	go_redis "github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	tracerProvider go_core_observ.TracerProvider
+	childLogger = log.With().Str("component","go-limit").Str("package","internal.adapter.redis").Logger()
+)
+
+// Above how long a transaction_id is remembered at least, a check remembers it for its longest window
+// A replay after that is a new transaction for the counters (the ledger still has its limit_request)
+const requestTtl = 24 * time.Hour
+
+// Above the extra time a window is kept after its last consumption
+const windowGrace = time.Minute
+
+// Above the buckets of a window, a rolling window keeps about windowBuckets buckets whatever its length
+const windowBuckets = 60
+
+// Above the functions shared by the scripts
+// A settled consumption is added to the bucket of its created_at (amount, quantity and first created at, per mcc)
+// and kept as a member of the settled sorted set (score = created_at in ms, member = amount|mcc|member),
+// read only at the edge of a window. The width of the buckets is kept on the buckets (field w), so it never
+// changes while the window lives
+const bucketFunctions = `
+local function bucket_width(buckets, default)
+	return tonumber(redis.call('HGET', buckets, 'w') or default)
+end
+
+local function add_settled(settled, buckets, width, created, amount, mcc, member)
+	local value = string.format('%.0f', amount) .. '|' .. mcc .. '|' .. member
+	if redis.call('ZSCORE', settled, value) then
+		return
+	end
+	redis.call('ZADD', settled, created, value)
+
+	local field = string.format('%.0f', math.floor(created / width) * width) .. '|' .. mcc
+	local sum, count, first = amount, 1, created
+	local previous = redis.call('HGET', buckets, field)
+	if previous then
+		local previous_sum, previous_count, previous_first = string.match(previous, '^(-?%d+)|(%d+)|(%d+)$')
+		sum = sum + tonumber(previous_sum)
+		count = count + tonumber(previous_count)
+		first = math.min(first, tonumber(previous_first))
+	end
+	redis.call('HSETNX', buckets, 'w', width)
+	redis.call('HSET', buckets, field, string.format('%.0f|%d|%.0f', sum, count, first))
+end
+
+local function copy_ttl(ttl, ...)
+	if ttl > 0 then
+		for _, key in ipairs({...}) do
+			if redis.call('PTTL', key) < ttl then
+				redis.call('PEXPIRE', key, ttl)
+			end
+		end
+	end
+end
+`
+
+// Above the script that evaluates and consumes all windows of a check
+// Each window has the settled consumption (sorted set and buckets, see bucketFunctions) and the reservations,
+// a sorted set (member = transaction, score = created_at in ms) plus a hash with amount|mcc|expires at of each one
+// A window sums the buckets entirely inside it, the settled members of the bucket cut by its start and the
+// reservations alive, only the ones of the mccs of the window (all of them without mccs)
+// KEYS: 4 per window (settled, buckets, reservations, details) and then the request key (optional)
+// ARGV: now, payload hash, request ttl, number of windows, simulate and then 12 per window
+// (window start, amount, limit, by quantity, pre commit, member, expires at, key ttl, created at, mcc, mccs counted, bucket width)
+// The mccs counted are separated by commas, empty counts all members
+// All windows are evaluated before any is consumed, a check breached (any window) consumes no window
+// A simulation (simulate = 1) is evaluated as a check, nothing is written, pruned, expired or remembered
+// It returns {"REPLAY", hash} or per window {usage amount, usage quantity, first created at, applied}
+var consumeScript = go_redis.NewScript(bucketFunctions + `
+local now = tonumber(ARGV[1])
+local n = tonumber(ARGV[4])
+local simulate = ARGV[5] == '1'
+local request_key = KEYS[n * 4 + 1]
+
+if request_key then
+	local previous = redis.call('GET', request_key)
+	if previous then
+		return {'REPLAY', previous}
+	end
+end
+
+local result = {}
+local windows = {}
+local breached = false
+for i = 0, n - 1 do
+	local settled, buckets, reservations, details = KEYS[i * 4 + 1], KEYS[i * 4 + 2], KEYS[i * 4 + 3], KEYS[i * 4 + 4]
+	local a = 6 + i * 12
+	local window_start = tonumber(ARGV[a])
+	local width = bucket_width(buckets, ARGV[a + 11])
+	local first_full = math.ceil(window_start / width) * width
+
+	local scope = nil
+	if ARGV[a + 10] ~= '' then
+		scope = {}
+		for mcc in string.gmatch(ARGV[a + 10], '[^,]+') do
+			scope[mcc] = true
+		end
+	end
+
+	local sum, count, first = 0, 0, nil
+	local function count_usage(mcc, amount, quantity, created)
+		if scope == nil or scope[mcc] then
+			sum = sum + amount
+			count = count + quantity
+			if first == nil or created < first then
+				first = created
+			end
+		end
+	end
+
+	-- the buckets entirely inside the window, the ones that left the window are pruned
+	local fields = redis.call('HGETALL', buckets)
+	for j = 1, #fields, 2 do
+		local start, mcc = string.match(fields[j], '^(%d+)|(.*)$')
+		if start then
+			start = tonumber(start)
+			if start >= first_full and start <= now then
+				local bucket_sum, bucket_count, bucket_first = string.match(fields[j + 1], '^(-?%d+)|(%d+)|(%d+)$')
+				count_usage(mcc, tonumber(bucket_sum), tonumber(bucket_count), tonumber(bucket_first))
+			elseif start + width <= window_start and not simulate then
+				redis.call('HDEL', buckets, fields[j])
+			end
+		end
+	end
+
+	-- the settled members of the bucket cut by the start of the window
+	if not simulate then
+		redis.call('ZREMRANGEBYSCORE', settled, '-inf', '(' .. ARGV[a])
+	end
+	local edge_end = '(' .. string.format('%.0f', first_full)
+	if first_full > now then
+		edge_end = ARGV[1]
+	end
+	local edge = redis.call('ZRANGEBYSCORE', settled, ARGV[a], edge_end, 'WITHSCORES')
+	for j = 1, #edge, 2 do
+		local amount, mcc = string.match(edge[j], '^(-?%d+)|([^|]*)|')
+		count_usage(mcc, tonumber(amount), 1, tonumber(edge[j + 1]))
+	end
+
+	-- the reservations alive inside the window, a reservation expired (or out of the window) is pruned
+	if not simulate then
+		local old = redis.call('ZRANGEBYSCORE', reservations, '-inf', '(' .. ARGV[a])
+		if #old > 0 then
+			redis.call('ZREMRANGEBYSCORE', reservations, '-inf', '(' .. ARGV[a])
+			redis.call('HDEL', details, unpack(old))
+		end
+	end
+	local alive = redis.call('ZRANGEBYSCORE', reservations, ARGV[a], ARGV[1], 'WITHSCORES')
+	for j = 1, #alive, 2 do
+		local amount, mcc, expire_at = string.match(redis.call('HGET', details, alive[j]) or '', '^(-?%d+)|([^|]*)|(%d+)$')
+		if amount and tonumber(expire_at) >= now then
+			count_usage(mcc, tonumber(amount), 1, tonumber(alive[j + 1]))
+		elseif not simulate then
+			redis.call('ZREM', reservations, alive[j])
+			redis.call('HDEL', details, alive[j])
+		end
+	end
+
+	local amount, limit = tonumber(ARGV[a + 1]), tonumber(ARGV[a + 2])
+	local consumed = sum
+	if ARGV[a + 3] == '1' then
+		consumed = count * amount
+	end
+	local breach
+	if ARGV[a + 4] == '1' then
+		breach = consumed + amount > limit
+	else
+		breach = consumed > limit
+	end
+
+	if breach then
+		breached = true
+	end
+
+	local first_create_at = ''
+	if first then
+		first_create_at = string.format('%.0f', first)
+	end
+
+	table.insert(windows, {settled, buckets, reservations, details, a, width})
+	table.insert(result, {string.format('%.0f', sum), count, first_create_at, 0})
+end
+
+-- consume every window, only when no window is breached
+for i, val in ipairs(windows) do
+	local settled, buckets, reservations, details, a, width = unpack(val)
+	if not breached then
+		if not simulate then
+			local member, created = ARGV[a + 5], tonumber(ARGV[a + 8])
+			if ARGV[a + 6] ~= '0' then
+				redis.call('HSETNX', buckets, 'w', width)
+				redis.call('ZADD', reservations, created, member)
+				redis.call('HSET', details, member, ARGV[a + 1] .. '|' .. ARGV[a + 9] .. '|' .. ARGV[a + 6])
+			else
+				add_settled(settled, buckets, width, created, tonumber(ARGV[a + 1]), ARGV[a + 9], member)
+			end
+		end
+		result[i][4] = 1
+	end
+	if not simulate then
+		redis.call('PEXPIRE', settled, ARGV[a + 7])
+		redis.call('PEXPIRE', buckets, ARGV[a + 7])
+		redis.call('PEXPIRE', reservations, ARGV[a + 7])
+		redis.call('PEXPIRE', details, ARGV[a + 7])
+	end
+end
+
+if request_key and not simulate then
+	redis.call('SET', request_key, ARGV[2], 'PX', ARGV[3])
+end
+
+return result
+`)
+
+// Above the script that stores the decision of a transaction_id, kept as long as its request key
+// KEYS: request, decision
+// ARGV: decision, default ttl
+var decisionScript = go_redis.NewScript(`
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl <= 0 then
+	ttl = ARGV[2]
+end
+redis.call('SET', KEYS[2], ARGV[1], 'PX', ttl)
+return 1
+`)
+
+// Above the script that adds a settled member to a window (a reversal, negative amount)
+// A window already gone has nothing left to reverse, the keys written keep the ttl of the window
+// KEYS: settled, buckets
+// ARGV: created at, amount, mcc, member
+var addScript = go_redis.NewScript(bucketFunctions + `
+if redis.call('EXISTS', KEYS[2]) == 0 then
+	return 0
+end
+
+local ttl = redis.call('PTTL', KEYS[2])
+add_settled(KEYS[1], KEYS[2], bucket_width(KEYS[2], 60000), tonumber(ARGV[1]), tonumber(ARGV[2]), ARGV[3], ARGV[4])
+copy_ttl(ttl, KEYS[1], KEYS[2])
+return 1
+`)
+
+// Above the script that confirms a reservation, it becomes a settled member of its window (it never expires)
+// KEYS: settled, buckets, reservations, details
+// ARGV: member
+var confirmScript = go_redis.NewScript(bucketFunctions + `
+local detail = redis.call('HGET', KEYS[4], ARGV[1])
+local created = redis.call('ZSCORE', KEYS[3], ARGV[1])
+if not detail or not created then
+	return 0
+end
+
+local ttl = redis.call('PTTL', KEYS[3])
+local amount, mcc = string.match(detail, '^(-?%d+)|([^|]*)|')
+redis.call('ZREM', KEYS[3], ARGV[1])
+redis.call('HDEL', KEYS[4], ARGV[1])
+add_settled(KEYS[1], KEYS[2], bucket_width(KEYS[2], 60000), tonumber(created), tonumber(amount), mcc, ARGV[1])
+copy_ttl(ttl, KEYS[1], KEYS[2])
+return 1
+`)
+
+// Above a counter store using the redis protocol
+type CounterStore struct {
+	client	go_redis.UniversalClient
+}
+
+// Above the redis adapter of the counter store port
+var _ port.CounterStore = (*CounterStore)(nil)
+
+// Above new counter store
+func NewCounterStore(client go_redis.UniversalClient) *CounterStore{
+	childLogger.Info().Str("func","NewCounterStore").Send()
+
+	return &CounterStore{
+		client: client,
+	}
+}
+
+// Above open a redis client and check the connection
+func NewRedisClient(ctx context.Context, limitConfig model.LimitConfig) (go_redis.UniversalClient, error){
+	childLogger.Info().Str("func","NewRedisClient").Send()
+
+	client := go_redis.NewClient(&go_redis.Options{
+		Addr: limitConfig.RedisAddress,
+		Password: limitConfig.RedisPassword,
+	})
+
+	err := client.Ping(ctx).Err()
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return client, nil
+}
+
+// Above all keys of the counter store share the same hash tag, whatever the levels of a check
+// A script only touches keys of a single slot, so on a redis cluster the counter store lives in one slot (one node)
+const keyTag = "limit:{counter}:"
+
+// Above the prefix of the keys of a limit key
+func keyPrefix(key string) string {
+	return keyTag + key + ":"
+}
+
+// Above the keys of a transaction_id (request, decision), as limit_request a transaction_id is unique whatever its key
+func requestKeys(transactionId string) (string, string) {
+	return keyTag + "request:" + transactionId, keyTag + "decision:" + transactionId
+}
+
+// Above the keys of a window (settled, buckets, reservations, details), each window of a counter and each rule has its own
+func windowKeys(key string, limitWindow model.LimitWindow) (string, string, string, string) {
+	base := keyPrefix(key) + limitWindow.TypeLimit + ":" + limitWindow.OrderLimit + ":" + limitWindow.CounterLimit + ":" + limitWindow.Window
+	if limitWindow.RuleId > 0 {
+		base = base + ":" + strconv.Itoa(limitWindow.RuleId)
+	}
+	return base + ":s", base + ":b", base + ":r", base + ":d"
+}
+
+// Above the width of the buckets of a window, a calendar window uses its whole period
+func bucketWidth(limitWindow model.LimitWindow) time.Duration {
+	length := limitWindow.CreateAt.Sub(limitWindow.WindowStart)
+	if limitWindow.WindowEnd != nil {
+		length = limitWindow.WindowEnd.Sub(limitWindow.WindowStart)
+	}
+
+	width := (length / windowBuckets).Truncate(time.Second)
+	if width < time.Second {
+		width = time.Second
+	}
+	return width
+}
+
+// Above the milliseconds of a time, as used in the scores
+func epochMs(t time.Time) string {
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
+
+// Above evaluate and consume all windows of a check atomically
+func (c *CounterStore) ConsumeLimitCounter(ctx context.Context, limitCounter model.LimitCounter) (*model.LimitCounter, error){
+	childLogger.Info().Str("func","ConsumeLimitCounter").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "redis.ConsumeLimitCounter")
+	defer span.End()
+
+	now := time.Now()
+	keys := []string{}
+	simulate := "0"
+	if limitCounter.Simulate {
+		simulate = "1"
+	}
+	request_ttl := requestTtl
+	args := []any{	epochMs(now),
+					limitCounter.PayloadHash,
+					0,
+					len(limitCounter.Windows),
+					simulate,
+				}
+
+	for _, val := range limitCounter.Windows {
+		// a window of another level has its own key
+		window_key := limitCounter.Key
+		if val.Key != "" {
+			window_key = val.Key
+		}
+		settled, buckets, reservations, details := windowKeys(window_key, val)
+		keys = append(keys, settled, buckets, reservations, details)
+
+		by_quantity, pre_commit, expire_at := "0", "0", "0"
+		if val.ByQuantity {
+			by_quantity = "1"
+		}
+		if val.EvaluationMode == "PRE_COMMIT" {
+			pre_commit = "1"
+		}
+		if val.ExpireAt != nil {
+			expire_at = epochMs(*val.ExpireAt)
+		}
+		// a calendar window is kept until its end, a rolling one for its length
+		key_ttl := val.CreateAt.Sub(val.WindowStart) + windowGrace
+		if val.WindowEnd != nil {
+			key_ttl = val.WindowEnd.Sub(val.CreateAt) + windowGrace
+		}
+		// a window never expires before the grace, even if its end was already reached
+		if key_ttl < windowGrace {
+			key_ttl = windowGrace
+		}
+		if key_ttl > request_ttl {
+			request_ttl = key_ttl
+		}
+
+		args = append(args,	epochMs(val.WindowStart),
+							strconv.FormatInt(int64(val.Amount), 10),
+							strconv.FormatInt(int64(val.LimitAmount), 10),
+							by_quantity,
+							pre_commit,
+							val.Member,
+							expire_at,
+							key_ttl.Milliseconds(),
+							epochMs(val.CreateAt),
+							val.Mcc,
+							strings.Join(val.Mccs, ","),
+							bucketWidth(val).Milliseconds(),
+						)
+	}
+	if limitCounter.TransactionId != "" {
+		request_key, _ := requestKeys(limitCounter.TransactionId)
+		keys = append(keys, request_key)
+	}
+	// the transaction_id is remembered while it may still be counted by a window
+	args[2] = request_ttl.Milliseconds()
+
+	// execute
+	res, err := consumeScript.Run(ctx, c.client, keys, args...).Slice()
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// the transaction_id was already evaluated
+	if len(res) == 2 {
+		if replay, ok := res[0].(string); ok && replay == "REPLAY" {
+			limitCounter.Replay = true
+			limitCounter.ReplayHash, _ = res[1].(string)
+			return &limitCounter, nil
+		}
+	}
+
+	if len(res) != len(limitCounter.Windows) {
+		return nil, errors.New("unexpected result of the counter script")
+	}
+
+	for i, val := range res {
+		row, ok := val.([]any)
+		if !ok || len(row) != 4 {
+			return nil, errors.New("unexpected result of the counter script")
+		}
+
+		usage_amount, _ := row[0].(string)
+		usage_quantity, _ := row[1].(int64)
+		first_create_at, _ := row[2].(string)
+		applied, _ := row[3].(int64)
+
+		tmp_amount, err := strconv.ParseInt(usage_amount, 10, 64)
+		if err != nil {
+			return nil, errors.New(err.Error())
+		}
+		limitCounter.Windows[i].Usage = model.LimitUsage{Amount: model.Money(tmp_amount), Quantity: int(usage_quantity)}
+
+		if first_create_at != "" {
+			tmp_ms, err := strconv.ParseFloat(first_create_at, 64)
+			if err != nil {
+				return nil, errors.New(err.Error())
+			}
+			tmp_first := time.UnixMilli(int64(tmp_ms))
+			limitCounter.Windows[i].Usage.FirstCreateAt = &tmp_first
+		}
+		limitCounter.Windows[i].Applied = applied == 1
+	}
+
+	return &limitCounter, nil
+}
+
+// Above add a member to a window, used by the reversals (negative amount)
+func (c *CounterStore) AddLimitWindow(ctx context.Context, key string, limitWindow model.LimitWindow) error{
+	childLogger.Info().Str("func","AddLimitWindow").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "redis.AddLimitWindow")
+	defer span.End()
+
+	settled, buckets, _, _ := windowKeys(key, limitWindow)
+
+	err := addScript.Run(ctx, c.client, []string{settled, buckets},	epochMs(limitWindow.CreateAt),
+																		strconv.FormatInt(int64(limitWindow.Amount), 10),
+																		limitWindow.Mcc,
+																		limitWindow.Member).Err()
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	return nil
+}
+
+// Above remove a reservation of a window, used when a reservation is released
+func (c *CounterStore) RemoveLimitWindow(ctx context.Context, key string, limitWindow model.LimitWindow) error{
+	childLogger.Info().Str("func","RemoveLimitWindow").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "redis.RemoveLimitWindow")
+	defer span.End()
+
+	_, _, reservations, details := windowKeys(key, limitWindow)
+
+	_, err := c.client.TxPipelined(ctx, func(pipe go_redis.Pipeliner) error {
+		pipe.ZRem(ctx, reservations, limitWindow.Member)
+		pipe.HDel(ctx, details, limitWindow.Member)
+		return nil
+	})
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	return nil
+}
+
+// Above confirm a reservation of a window, a reservation confirmed never expires
+func (c *CounterStore) ConfirmLimitWindow(ctx context.Context, key string, limitWindow model.LimitWindow) error{
+	childLogger.Info().Str("func","ConfirmLimitWindow").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "redis.ConfirmLimitWindow")
+	defer span.End()
+
+	settled, buckets, reservations, details := windowKeys(key, limitWindow)
+
+	err := confirmScript.Run(ctx, c.client, []string{settled, buckets, reservations, details}, limitWindow.Member).Err()
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	return nil
+}
+
+// Above get the decision of a transaction_id already evaluated
+func (c *CounterStore) GetLimitDecision(ctx context.Context, transactionId string) (*model.LimitDecision, error){
+	childLogger.Info().Str("func","GetLimitDecision").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "redis.GetLimitDecision")
+	defer span.End()
+
+	_, decision_key := requestKeys(transactionId)
+
+	res, err := c.client.Get(ctx, decision_key).Bytes()
+	if err == go_redis.Nil {
+		return nil, erro.ErrNotFound
+	}
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	limitDecision := model.LimitDecision{}
+	err = json.Unmarshal(res, &limitDecision)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return &limitDecision, nil
+}
+
+// Above store the decision of a transaction_id
+func (c *CounterStore) AddLimitDecision(ctx context.Context, limitDecision model.LimitDecision) error{
+	childLogger.Info().Str("func","AddLimitDecision").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "redis.AddLimitDecision")
+	defer span.End()
+
+	payload, err := json.Marshal(limitDecision)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	request_key, decision_key := requestKeys(limitDecision.TransactionId)
+
+	err = decisionScript.Run(ctx, c.client, []string{request_key, decision_key}, payload, requestTtl.Milliseconds()).Err()
+	if err != nil {
+		return errors.New(err.Error())
+	}
+
+	return nil
+}
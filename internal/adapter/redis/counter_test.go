@@ -0,0 +1,375 @@
+package redis
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	go_redis "github.com/redis/go-redis/v9"
+
+	"github.com/go-limit/internal/core/model"
+)
+
+// a counter store over an in-process redis
+func newTestCounterStore(t *testing.T) (*CounterStore, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := go_redis.NewClient(&go_redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewCounterStore(client), server
+}
+
+// a DAY window of the value of card-1 with the projected total (PRE_COMMIT)
+func testWindow(member string, amount int64, limitAmount int64, now time.Time) model.LimitWindow {
+	return model.LimitWindow{	Key: "card-1",
+								TypeLimit: "CREDIT",
+								OrderLimit: "CREDIT",
+								CounterLimit: "VALUE",
+								Window: "DAY",
+								Member: member,
+								WindowStart: now.Add(-24 * time.Hour),
+								Amount: model.MoneyFromInt(amount),
+								LimitAmount: model.MoneyFromInt(limitAmount),
+								EvaluationMode: "PRE_COMMIT",
+								CreateAt: now,
+							}
+}
+
+func mustConsume(t *testing.T, counterStore *CounterStore, limitCounter model.LimitCounter) *model.LimitCounter {
+	t.Helper()
+
+	res, err := counterStore.ConsumeLimitCounter(context.Background(), limitCounter)
+	if err != nil {
+		t.Fatalf("ConsumeLimitCounter: %v", err)
+	}
+	return res
+}
+
+// the members of a window, settled (amount|mcc|member) and reserved
+func windowMembers(t *testing.T, server *miniredis.Miniredis, limitWindow model.LimitWindow) []string {
+	t.Helper()
+
+	settled, _, reservations, _ := windowKeys(limitWindow.Key, limitWindow)
+	members := []string{}
+	for _, key := range []string{settled, reservations} {
+		if !server.Exists(key) {
+			continue
+		}
+		list_member, err := server.ZMembers(key)
+		if err != nil {
+			t.Fatalf("ZMembers: %v", err)
+		}
+		for _, val := range list_member {
+			if key == settled {
+				val = strings.SplitN(val, "|", 3)[2]
+			}
+			members = append(members, val)
+		}
+	}
+	return members
+}
+
+func TestConsumeLimitCounterBreach(t *testing.T) {
+	counterStore, server := newTestCounterStore(t)
+	now := time.Now()
+
+	res := mustConsume(t, counterStore, model.LimitCounter{Key: "card-1", Windows: []model.LimitWindow{testWindow("m1", 60, 100, now)}})
+	if !res.Windows[0].Applied || res.Windows[0].Usage.Amount != 0 {
+		t.Errorf("first window %+v, want applied over an empty usage", res.Windows[0])
+	}
+
+	res = mustConsume(t, counterStore, model.LimitCounter{Key: "card-1", Windows: []model.LimitWindow{testWindow("m2", 50, 100, now)}})
+	if res.Windows[0].Applied || res.Windows[0].Usage.Amount != model.MoneyFromInt(60) || res.Windows[0].Usage.Quantity != 1 {
+		t.Errorf("breached window %+v, want not applied over a usage of 60", res.Windows[0])
+	}
+
+	res = mustConsume(t, counterStore, model.LimitCounter{Key: "card-1", Windows: []model.LimitWindow{testWindow("m3", 40, 100, now)}})
+	if !res.Windows[0].Applied {
+		t.Errorf("window %+v, want applied up to the limit", res.Windows[0])
+	}
+	if members := windowMembers(t, server, testWindow("", 0, 0, now)); len(members) != 2 {
+		t.Errorf("members %v, want m1 and m3", members)
+	}
+}
+
+// a check breached by a window consumes none of its windows
+func TestConsumeLimitCounterBreachConsumesNothing(t *testing.T) {
+	counterStore, server := newTestCounterStore(t)
+	now := time.Now()
+
+	day := testWindow("m1", 150, 100, now)
+	month := testWindow("m1", 150, 1000, now)
+	month.Window = "MONTH"
+	month.WindowStart = now.AddDate(0, -1, 0)
+
+	res := mustConsume(t, counterStore, model.LimitCounter{Key: "card-1", Windows: []model.LimitWindow{month, day}})
+	for _, val := range res.Windows {
+		if val.Applied {
+			t.Errorf("window %s applied, want no window applied", val.Window)
+		}
+	}
+	if members := windowMembers(t, server, month); len(members) != 0 {
+		t.Errorf("members of the MONTH %v, want none", members)
+	}
+}
+
+// a transaction_id already evaluated is not consumed again
+func TestConsumeLimitCounterReplay(t *testing.T) {
+	counterStore, server := newTestCounterStore(t)
+	now := time.Now()
+
+	limit_counter := model.LimitCounter{	TransactionId: "tx-1",
+											Key: "card-1",
+											PayloadHash: "hash-1",
+											Windows: []model.LimitWindow{testWindow("m1", 10, 100, now)},
+										}
+	res := mustConsume(t, counterStore, limit_counter)
+	if res.Replay {
+		t.Fatalf("first evaluation is a replay")
+	}
+
+	limit_counter.PayloadHash = "hash-2"
+	limit_counter.Windows = []model.LimitWindow{testWindow("m2", 10, 100, now)}
+	res = mustConsume(t, counterStore, limit_counter)
+	if !res.Replay || res.ReplayHash != "hash-1" {
+		t.Errorf("replay %v hash %s, want a replay of hash-1", res.Replay, res.ReplayHash)
+	}
+	if members := windowMembers(t, server, testWindow("", 0, 0, now)); len(members) != 1 {
+		t.Errorf("members %v, want only m1", members)
+	}
+}
+
+// a reservation is counted until it expires, it is given back when released and kept when confirmed
+func TestConsumeLimitCounterReservation(t *testing.T) {
+	ctx := context.Background()
+	counterStore, _ := newTestCounterStore(t)
+	now := time.Now()
+
+	expired := testWindow("m1", 30, 100, now.Add(-time.Minute))
+	expire_at := now.Add(-time.Second)
+	expired.ExpireAt = &expire_at
+	alive := testWindow("m2", 40, 100, now)
+	expire_at_alive := now.Add(time.Minute)
+	alive.ExpireAt = &expire_at_alive
+	released := testWindow("m3", 20, 100, now)
+	released.ExpireAt = &expire_at_alive
+	mustConsume(t, counterStore, model.LimitCounter{Key: "card-1", Windows: []model.LimitWindow{expired}})
+	mustConsume(t, counterStore, model.LimitCounter{Key: "card-1", Windows: []model.LimitWindow{alive}})
+	mustConsume(t, counterStore, model.LimitCounter{Key: "card-1", Windows: []model.LimitWindow{released}})
+
+	usage := func() model.LimitUsage {
+		res := mustConsume(t, counterStore, model.LimitCounter{Key: "card-1", Simulate: true, Windows: []model.LimitWindow{testWindow("probe", 0, 100, now)}})
+		return res.Windows[0].Usage
+	}
+	if res_usage := usage(); res_usage.Amount != model.MoneyFromInt(60) {
+		t.Errorf("usage %v, want only the reservations alive (60)", res_usage.Amount)
+	}
+
+	if err := counterStore.ConfirmLimitWindow(ctx, "card-1", alive); err != nil {
+		t.Fatalf("ConfirmLimitWindow: %v", err)
+	}
+	if err := counterStore.RemoveLimitWindow(ctx, "card-1", released); err != nil {
+		t.Fatalf("RemoveLimitWindow: %v", err)
+	}
+	if res_usage := usage(); res_usage.Amount != model.MoneyFromInt(40) || res_usage.Quantity != 1 {
+		t.Errorf("usage %+v, want the reservation confirmed (40)", res_usage)
+	}
+
+	// a reservation confirmed is settled, it is not confirmed twice and never expires
+	if err := counterStore.ConfirmLimitWindow(ctx, "card-1", alive); err != nil {
+		t.Fatalf("ConfirmLimitWindow: %v", err)
+	}
+	if res_usage := usage(); res_usage.Amount != model.MoneyFromInt(40) || res_usage.Quantity != 1 {
+		t.Errorf("usage %+v after a second confirm, want 40", res_usage)
+	}
+}
+
+// a window only counts the members of its mccs, its rule and its window
+func TestConsumeLimitCounterScope(t *testing.T) {
+	counterStore, _ := newTestCounterStore(t)
+	now := time.Now()
+
+	restaurant := testWindow("m1", 30, 100, now)
+	restaurant.Mcc = "5812"
+	grocery := testWindow("m2", 20, 100, now)
+	grocery.Mcc = "5411"
+	rule := testWindow("m3", 10, 100, now)
+	rule.RuleId = 7
+	month := testWindow("m4", 5, 100, now)
+	month.Window = "MONTH"
+	mustConsume(t, counterStore, model.LimitCounter{Key: "card-1", Windows: []model.LimitWindow{restaurant, grocery, rule, month}})
+
+	probe_mcc := testWindow("probe", 0, 100, now)
+	probe_mcc.Mccs = []string{"5812", "5813"}
+	probe_rule := testWindow("probe", 0, 100, now)
+	probe_rule.RuleId = 7
+	probe_month := testWindow("probe", 0, 100, now)
+	probe_month.Window = "MONTH"
+	res := mustConsume(t, counterStore, model.LimitCounter{	Key: "card-1",
+															Simulate: true,
+															Windows: []model.LimitWindow{testWindow("probe", 0, 100, now), probe_mcc, probe_rule, probe_month},
+														})
+
+	for i, want := range []int64{50, 30, 10, 5} {
+		if res.Windows[i].Usage.Amount != model.MoneyFromInt(want) {
+			t.Errorf("usage of the window %d = %v, want %d", i, res.Windows[i].Usage.Amount, want)
+		}
+	}
+}
+
+// a simulation evaluates as a check but leaves nothing behind
+func TestConsumeLimitCounterSimulate(t *testing.T) {
+	counterStore, server := newTestCounterStore(t)
+	now := time.Now()
+
+	mustConsume(t, counterStore, model.LimitCounter{Key: "card-1", Windows: []model.LimitWindow{testWindow("m1", 60, 100, now)}})
+
+	// the member m1 already exists, the simulation must not remove it
+	res := mustConsume(t, counterStore, model.LimitCounter{	TransactionId: "tx-2",
+															Key: "card-1",
+															PayloadHash: "hash-2",
+															Simulate: true,
+															Windows: []model.LimitWindow{testWindow("m1", 10, 100, now), testWindow("m2", 10, 100, now)},
+														})
+	if !res.Windows[0].Applied || !res.Windows[1].Applied {
+		t.Errorf("windows %+v, want applied", res.Windows)
+	}
+	if members := windowMembers(t, server, testWindow("", 0, 0, now)); len(members) != 1 || members[0] != "m1" {
+		t.Errorf("members %v, want only m1", members)
+	}
+
+	// the transaction_id of a simulation is not remembered
+	res = mustConsume(t, counterStore, model.LimitCounter{	TransactionId: "tx-2",
+															Key: "card-1",
+															PayloadHash: "hash-2",
+															Windows: []model.LimitWindow{testWindow("m2", 10, 100, now)},
+														})
+	if res.Replay || !res.Windows[0].Applied {
+		t.Errorf("check after the simulation %+v, want applied and not a replay", res)
+	}
+}
+
+// a window sums the buckets entirely inside it and, member by member, the bucket cut by its start
+func TestConsumeLimitCounterBuckets(t *testing.T) {
+	counterStore, server := newTestCounterStore(t)
+
+	// the start of the HOUR window is in the middle of a bucket (1 minute)
+	now := time.UnixMilli(time.Now().Add(-time.Minute).UnixMilli() / 60000 * 60000 + 30000)
+	window_start := now.Add(-time.Hour)
+
+	consume := func(member string, amount int64, createAt time.Time) {
+		limit_window := testWindow(member, amount, 1000, createAt)
+		limit_window.Window = "HOUR"
+		limit_window.WindowStart = createAt.Add(-time.Hour)
+		mustConsume(t, counterStore, model.LimitCounter{Key: "card-1", Windows: []model.LimitWindow{limit_window}})
+	}
+	consume("m1", 100, window_start.Add(-2 * time.Hour))
+	consume("m2", 10, window_start.Add(-10 * time.Second))
+	consume("m3", 20, window_start.Add(10 * time.Second))
+	consume("m4", 30, now.Add(-30 * time.Minute))
+	consume("m5", 40, now.Add(-30 * time.Minute))
+
+	probe := testWindow("probe", 0, 1000, now)
+	probe.Window = "HOUR"
+	probe.WindowStart = window_start
+	res := mustConsume(t, counterStore, model.LimitCounter{Key: "card-1", Simulate: true, Windows: []model.LimitWindow{probe}})
+	usage := res.Windows[0].Usage
+	if usage.Amount != model.MoneyFromInt(90) || usage.Quantity != 3 {
+		t.Errorf("usage %+v, want 90 of m3, m4 and m5", usage)
+	}
+	if usage.FirstCreateAt == nil || !usage.FirstCreateAt.Equal(window_start.Add(10 * time.Second)) {
+		t.Errorf("first created at %v, want the one of m3", usage.FirstCreateAt)
+	}
+
+	// the bucket of m4 and m5 holds both, the bucket of m1 left the window of the next checks and was pruned
+	_, buckets, _, _ := windowKeys("card-1", probe)
+	if fields, _ := server.HKeys(buckets); len(fields) != 3 {
+		t.Errorf("fields of the buckets %v, want the width and the buckets of m2 (and m3) and m4 (and m5)", fields)
+	}
+	probe.Amount = model.MoneyFromInt(1)
+	mustConsume(t, counterStore, model.LimitCounter{Key: "card-1", Windows: []model.LimitWindow{probe}})
+	if fields, _ := server.HKeys(buckets); len(fields) != 4 {
+		t.Errorf("fields of the buckets %v, want the width and the buckets of m2 (and m3), m4 (and m5) and the probe", fields)
+	}
+}
+
+// every key of a check shares a single hash tag (whatever the levels) and expires
+func TestConsumeLimitCounterKeys(t *testing.T) {
+	ctx := context.Background()
+	counterStore, server := newTestCounterStore(t)
+	now := time.Now()
+
+	card := testWindow("m1", 10, 100, now)
+	account := testWindow("m1", 10, 100, now)
+	account.Key = "account-1"
+	reserved := testWindow("m1", 10, 100, now)
+	reserved.Window = "HOUR"
+	reserved.WindowStart = now.Add(-time.Hour)
+	expire_at := now.Add(time.Minute)
+	reserved.ExpireAt = &expire_at
+	mustConsume(t, counterStore, model.LimitCounter{TransactionId: "tx-1", Key: "card-1", PayloadHash: "hash-1", Windows: []model.LimitWindow{card, account, reserved}})
+
+	// a reversal of a window still alive keeps its ttl, a window already gone is not created again
+	reversal := testWindow("m1|R1", -10, 0, now)
+	if err := counterStore.AddLimitWindow(ctx, "card-1", reversal); err != nil {
+		t.Fatalf("AddLimitWindow: %v", err)
+	}
+	gone := testWindow("m1|R1", -10, 0, now)
+	gone.Window = "MONTH"
+	if err := counterStore.AddLimitWindow(ctx, "card-1", gone); err != nil {
+		t.Fatalf("AddLimitWindow: %v", err)
+	}
+	if members := windowMembers(t, server, gone); len(members) != 0 {
+		t.Errorf("members of a window gone %v, want none", members)
+	}
+
+	for _, key := range server.Keys() {
+		if !strings.HasPrefix(key, keyTag) {
+			t.Errorf("key %s without the hash tag %s", key, keyTag)
+		}
+		if server.TTL(key) <= 0 {
+			t.Errorf("key %s without ttl", key)
+		}
+	}
+	if members := windowMembers(t, server, card); len(members) != 2 {
+		t.Errorf("members %v, want m1 and its reversal", members)
+	}
+}
+
+// a transaction_id is remembered whatever its key, for the longest window of its check
+func TestConsumeLimitCounterRequest(t *testing.T) {
+	ctx := context.Background()
+	counterStore, server := newTestCounterStore(t)
+	now := time.Now()
+
+	month := testWindow("m1", 10, 100, now)
+	month.Window = "MONTH"
+	month.WindowStart = now.AddDate(0, -1, 0)
+	mustConsume(t, counterStore, model.LimitCounter{TransactionId: "tx-1", Key: "card-1", PayloadHash: "hash-1", Windows: []model.LimitWindow{month}})
+	if err := counterStore.AddLimitDecision(ctx, model.LimitDecision{TransactionId: "tx-1", Decision: "APPROVED"}); err != nil {
+		t.Fatalf("AddLimitDecision: %v", err)
+	}
+
+	request_key, decision_key := requestKeys("tx-1")
+	if ttl := server.TTL(request_key); ttl < now.Sub(month.WindowStart) {
+		t.Errorf("ttl of the request %v, want at least the MONTH", ttl)
+	}
+	if server.TTL(decision_key) != server.TTL(request_key) {
+		t.Errorf("ttl of the decision %v, want the one of the request %v", server.TTL(decision_key), server.TTL(request_key))
+	}
+
+	other := testWindow("m1", 10, 100, now)
+	other.Key = "card-2"
+	res := mustConsume(t, counterStore, model.LimitCounter{TransactionId: "tx-1", Key: "card-2", PayloadHash: "hash-1", Windows: []model.LimitWindow{other}})
+	if !res.Replay {
+		t.Errorf("the transaction_id with another key is not a replay")
+	}
+	res_limit_decision, err := counterStore.GetLimitDecision(ctx, "tx-1")
+	if err != nil || res_limit_decision.Decision != "APPROVED" {
+		t.Errorf("GetLimitDecision: %+v %v, want the decision approved", res_limit_decision, err)
+	}
+}
@@ -8,4 +8,9 @@ var (
 	ErrNotFound 		= errors.New("item not found")
 	ErrBadRequest 		= errors.New("bad request ! check parameters")
 	ErrTimeout			= errors.New("timeout: context deadline exceeded.")
+	ErrInvalidWindow	= errors.New("invalid order limit window")
+	ErrConflict			= errors.New("conflict: item already exists with a different content")
+	ErrInvalidAmount	= errors.New("invalid amount: a decimal with at most 2 fractional digits")
+	ErrFxRate			= errors.New("fx rate not available for the currency")
+	ErrExpression		= errors.New("invalid expression")
 )
\ No newline at end of file
@@ -0,0 +1,136 @@
+package expression
+
+import(
+	"strings"
+	"unicode"
+)
+
+// About the kinds of token of an expression
+const (
+	tokenEnd	= iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenClock
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+// About a token and its position (the column of its first character, starting at 1)
+type token struct {
+	kind	int
+	text	string
+	pos		int
+}
+
+// About split an expression in tokens
+// Strings are quoted with ' (a quote inside is doubled), a clock is written as hh:mm
+func tokenize(source string) ([]token, error){
+	runes := []rune(source)
+	tokens := []token{}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: pos})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: pos})
+			i++
+
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: pos})
+			i++
+
+		case r == '=':
+			tokens = append(tokens, token{kind: tokenOperator, text: "=", pos: pos})
+			i++
+
+		case r == '!' || r == '<' || r == '>':
+			next := rune(0)
+			if i + 1 < len(runes) {
+				next = runes[i + 1]
+			}
+			text := string(r)
+			switch {
+			case next == '=':
+				text = text + "="
+			case r == '<' && next == '>':
+				text = "<>"
+			case r == '!':
+				return nil, newError(pos, "unexpected character '!'")
+			}
+			i = i + len(text)
+			// <> is the same as !=
+			if text == "<>" {
+				text = "!="
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: text, pos: pos})
+
+		case r == '\'':
+			var text strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\'' {
+					if i + 1 < len(runes) && runes[i + 1] == '\'' {
+						text.WriteRune('\'')
+						i = i + 2
+						continue
+					}
+					closed = true
+					i++
+					break
+				}
+				text.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, newError(pos, "unterminated string")
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text.String(), pos: pos})
+
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			// a clock (hh:mm)
+			if i < len(runes) && runes[i] == ':' {
+				i++
+				minute_start := i
+				for i < len(runes) && unicode.IsDigit(runes[i]) {
+					i++
+				}
+				if i - minute_start != 2 {
+					return nil, newError(pos, "invalid time, expected hh:mm")
+				}
+				tokens = append(tokens, token{kind: tokenClock, text: string(runes[start:i]), pos: pos})
+				continue
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), pos: pos})
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: pos})
+
+		default:
+			return nil, newError(pos, "unexpected character '" + string(r) + "'")
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEnd, pos: len(runes) + 1})
+	return tokens, nil
+}
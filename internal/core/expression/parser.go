@@ -0,0 +1,280 @@
+package expression
+
+import(
+	"strings"
+)
+
+// About a node of the syntax tree, with the position of its first token
+type node interface {
+	position() int
+}
+
+// About a literal (number, string or clock)
+type literalNode struct {
+	kind	int
+	text	string
+	pos		int
+}
+
+// About a name, a field of the check or an attribute
+type identNode struct {
+	name	string
+	pos		int
+}
+
+// About and, or
+type logicalNode struct {
+	op		string
+	left	node
+	right	node
+	pos		int
+}
+
+// About not
+type notNode struct {
+	operand	node
+	pos		int
+}
+
+// About =, !=, <, <=, >, >=
+type compareNode struct {
+	op		string
+	left	node
+	right	node
+	pos		int
+}
+
+// About [not] in (a, b, ...)
+type inNode struct {
+	left	node
+	list	[]node
+	negate	bool
+	pos		int
+}
+
+// About [not] between a and b
+type betweenNode struct {
+	left	node
+	low		node
+	high	node
+	negate	bool
+	pos		int
+}
+
+func (n literalNode) position() int { return n.pos }
+func (n identNode) position() int { return n.pos }
+func (n logicalNode) position() int { return n.pos }
+func (n notNode) position() int { return n.pos }
+func (n compareNode) position() int { return n.pos }
+func (n inNode) position() int { return n.pos }
+func (n betweenNode) position() int { return n.pos }
+
+// About a recursive descent parser, the grammar (lowest precedence first):
+//   or      = and { "or" and }
+//   and     = not { "and" not }
+//   not     = "not" not | compare
+//   compare = operand [ op operand | ["not"] "in" "(" operand { "," operand } ")" | ["not"] "between" operand "and" operand ]
+//   operand = number | string | clock | name | "(" or ")"
+type parser struct {
+	tokens	[]token
+	current	int
+}
+
+// About parse an expression in a syntax tree
+func parse(source string) (node, error){
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEnd {
+		return nil, newError(p.peek().pos, "empty expression")
+	}
+
+	res_node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokenEnd {
+		return nil, newError(p.peek().pos, "unexpected '" + p.peek().text + "'")
+	}
+
+	return res_node, nil
+}
+
+// About the token not consumed yet
+func (p *parser) peek() token {
+	return p.tokens[p.current]
+}
+
+// About consume a token
+func (p *parser) next() token {
+	tok := p.tokens[p.current]
+	if tok.kind != tokenEnd {
+		p.current++
+	}
+	return tok
+}
+
+// About check if the next token is a keyword (case insensitive)
+func (p *parser) isKeyword(keyword string) bool {
+	tok := p.peek()
+	return tok.kind == tokenIdent && strings.EqualFold(tok.text, keyword)
+}
+
+// About the description of a token for the errors
+func describe(tok token) string {
+	if tok.kind == tokenEnd {
+		return "end of expression"
+	}
+	return "'" + tok.text + "'"
+}
+
+func (p *parser) parseOr() (node, error){
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		tok := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{op: "or", left: left, right: right, pos: tok.pos}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error){
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		tok := p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{op: "and", left: left, right: right, pos: tok.pos}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error){
+	if p.isKeyword("not") {
+		tok := p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand, pos: tok.pos}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *parser) parseCompare() (node, error){
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek().kind == tokenOperator {
+		tok := p.next()
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{op: tok.text, left: left, right: right, pos: tok.pos}, nil
+	}
+
+	negate := false
+	pos := p.peek().pos
+	if p.isKeyword("not") {
+		p.next()
+		negate = true
+		if !p.isKeyword("in") && !p.isKeyword("between") {
+			return nil, newError(p.peek().pos, "expected 'in' or 'between' after 'not', found " + describe(p.peek()))
+		}
+	}
+
+	switch {
+	case p.isKeyword("in"):
+		p.next()
+		if p.peek().kind != tokenLParen {
+			return nil, newError(p.peek().pos, "expected '(' after 'in', found " + describe(p.peek()))
+		}
+		p.next()
+		list := []node{}
+		for {
+			item, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+			if p.peek().kind == tokenComma {
+				p.next()
+				continue
+			}
+			if p.peek().kind != tokenRParen {
+				return nil, newError(p.peek().pos, "expected ',' or ')', found " + describe(p.peek()))
+			}
+			p.next()
+			break
+		}
+		return inNode{left: left, list: list, negate: negate, pos: pos}, nil
+
+	case p.isKeyword("between"):
+		p.next()
+		low, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isKeyword("and") {
+			return nil, newError(p.peek().pos, "expected 'and' in 'between', found " + describe(p.peek()))
+		}
+		p.next()
+		high, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return betweenNode{left: left, low: low, high: high, negate: negate, pos: pos}, nil
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseOperand() (node, error){
+	tok := p.peek()
+
+	switch tok.kind {
+	case tokenNumber, tokenString, tokenClock:
+		p.next()
+		return literalNode{kind: tok.kind, text: tok.text, pos: tok.pos}, nil
+
+	case tokenIdent:
+		for _, keyword := range []string{"and", "or", "not", "in", "between"} {
+			if strings.EqualFold(tok.text, keyword) {
+				return nil, newError(tok.pos, "unexpected '" + tok.text + "'")
+			}
+		}
+		p.next()
+		return identNode{name: tok.text, pos: tok.pos}, nil
+
+	case tokenLParen:
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokenRParen {
+			return nil, newError(p.peek().pos, "expected ')', found " + describe(p.peek()))
+		}
+		p.next()
+		return inner, nil
+	}
+
+	return nil, newError(tok.pos, "expected a value, found " + describe(tok))
+}
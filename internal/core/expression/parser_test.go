@@ -0,0 +1,116 @@
+package expression
+
+import(
+	"strings"
+	"testing"
+)
+
+// the syntax tree written with parentheses, to see the precedence
+func render(n node) string {
+	switch val := n.(type) {
+	case literalNode:
+		if val.kind == tokenString {
+			return "'" + val.text + "'"
+		}
+		return val.text
+	case identNode:
+		return val.name
+	case logicalNode:
+		return "(" + render(val.left) + " " + val.op + " " + render(val.right) + ")"
+	case notNode:
+		return "(not " + render(val.operand) + ")"
+	case compareNode:
+		return "(" + render(val.left) + " " + val.op + " " + render(val.right) + ")"
+	case inNode:
+		list := []string{}
+		for _, item := range val.list {
+			list = append(list, render(item))
+		}
+		op := "in"
+		if val.negate {
+			op = "not in"
+		}
+		return "(" + render(val.left) + " " + op + " [" + strings.Join(list, ", ") + "])"
+	case betweenNode:
+		op := "between"
+		if val.negate {
+			op = "not between"
+		}
+		return "(" + render(val.left) + " " + op + " " + render(val.low) + " " + render(val.high) + ")"
+	}
+	return "?"
+}
+
+func TestParsePrecedence(t *testing.T) {
+	cases := []struct {
+		source	string
+		want	string
+	}{
+		{"amount > 10", "(amount > 10)"},
+		// and binds tighter than or, not tighter than and
+		{"a = 1 or b = 2 and c = 3", "((a = 1) or ((b = 2) and (c = 3)))"},
+		{"a = 1 and b = 2 or c = 3", "(((a = 1) and (b = 2)) or (c = 3))"},
+		{"not a = 1 and b = 2", "((not (a = 1)) and (b = 2))"},
+		{"not not a = 1", "(not (not (a = 1)))"},
+		{"(a = 1 or b = 2) and c = 3", "(((a = 1) or (b = 2)) and (c = 3))"},
+		// left associative
+		{"a = 1 or b = 2 or c = 3", "(((a = 1) or (b = 2)) or (c = 3))"},
+		// the and of between is not a logical and
+		{"time between 22:00 and 06:00 and hour = 1", "((time between 22:00 06:00) and (hour = 1))"},
+		{"hour not between 1 and 5", "(hour not between 1 5)"},
+		{"mcc in ('5411', '5412')", "(mcc in ['5411', '5412'])"},
+		{"mcc not in ('5411')", "(mcc not in ['5411'])"},
+		// the keywords are case insensitive, <> is !=
+		{"a = 1 AND NOT b <> 'x''y'", "((a = 1) and (not (b != 'x'y')))"},
+		{"amount >= 10.5", "(amount >= 10.5)"},
+	}
+
+	for _, c := range cases {
+		res, err := parse(c.source)
+		if err != nil {
+			t.Errorf("parse(%q): %v", c.source, err)
+			continue
+		}
+		if render(res) != c.want {
+			t.Errorf("parse(%q) = %s, want %s", c.source, render(res), c.want)
+		}
+	}
+}
+
+// the errors have the position (the column, starting at 1) of the token where they were found
+func TestParseError(t *testing.T) {
+	cases := []struct {
+		source		string
+		position	int
+		message		string
+	}{
+		{"", 1, "empty expression"},
+		{"   ", 4, "empty expression"},
+		{"amount >", 9, "expected a value, found end of expression"},
+		{"amount > > 1", 10, "expected a value, found '>'"},
+		{"(amount > 1", 12, "expected ')', found end of expression"},
+		{"amount > 1)", 11, "unexpected ')'"},
+		{"amount > 1 amount", 12, "unexpected 'amount'"},
+		{"a = 1 and or b = 2", 11, "unexpected 'or'"},
+		{"a not = 1", 7, "expected 'in' or 'between' after 'not', found '='"},
+		{"mcc in '5411'", 8, "expected '(' after 'in', found '5411'"},
+		{"mcc in ('5411' '5412')", 16, "expected ',' or ')', found '5412'"},
+		{"hour between 1 5", 16, "expected 'and' in 'between', found '5'"},
+		{"key = 'abc", 7, "unterminated string"},
+		{"a ! b", 3, "unexpected character '!'"},
+		{"a = #", 5, "unexpected character '#'"},
+		{"time > 2:0", 8, "invalid time, expected hh:mm"},
+	}
+
+	for _, c := range cases {
+		_, err := parse(c.source)
+		res, ok := err.(*Error)
+		if !ok {
+			t.Errorf("parse(%q): %v, want an error at position %d", c.source, err, c.position)
+			continue
+		}
+		if res.Position != c.position || res.Message != c.message {
+			t.Errorf("parse(%q): %q at %d, want %q at %d", c.source, res.Message, res.Position, c.message, c.position)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package expression
+
+import(
+	"fmt"
+	"strings"
+	"strconv"
+	"math/big"
+	"time"
+
+	"github.com/go-limit/internal/core/model"
+)
+
+// About an error of an expression at a position (the column, starting at 1)
+type Error struct {
+	Position	int
+	Message		string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Position)
+}
+
+// About new error at a position
+func newError(pos int, message string) *Error {
+	return &Error{Position: pos, Message: message}
+}
+
+// About what an expression is evaluated against, the check and the time (in the timezone of the type limit)
+// The names amount, quantity, currency, mcc, key, time (hh:mm), hour and weekday (MON..SUN) are the fields
+// of the check (lowercase), an attribute is attributes.<name> and any other name is rejected
+type Env struct {
+	Amount		model.Money
+	Quantity	int
+	Currency	string
+	Mcc			string
+	Key			string
+	Time		time.Time
+	Attributes	map[string]string
+}
+
+// About the types of the values of an expression
+const (
+	kindBool	= iota
+	kindNumber
+	kindText
+	kindClock
+)
+
+// About the minor units of a major unit of an amount, from the scale of model.Money
+var moneyUnit = new(big.Int).Exp(big.NewInt(10), big.NewInt(model.MoneyScale), nil)
+
+// About the name of a type for the errors
+var kindName = map[int]string{kindBool: "condition", kindNumber: "number", kindText: "text", kindClock: "time"}
+
+// About a node compiled, only the function of its type is set
+type compiled struct {
+	kind	int
+	boolean	func(*Env) bool
+	number	func(*Env) *big.Rat
+	text	func(*Env) string
+	clock	func(*Env) int
+}
+
+// About an expression compiled, safe to be evaluated concurrently
+type Program struct {
+	source	string
+	eval	func(*Env) bool
+}
+
+// About the source of the program
+func (p *Program) String() string {
+	return p.source
+}
+
+// About evaluate the program
+func (p *Program) Eval(env Env) bool {
+	return p.eval(&env)
+}
+
+// About parse, check the types and compile an expression, the errors have the position where they were found
+func Compile(source string) (*Program, error){
+	res_node, err := parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	res_compiled, err := compile(res_node)
+	if err != nil {
+		return nil, err
+	}
+	if res_compiled.kind != kindBool {
+		return nil, newError(res_node.position(), "the expression must be a condition, found a " + kindName[res_compiled.kind])
+	}
+
+	return &Program{source: source, eval: res_compiled.boolean}, nil
+}
+
+// About compile a node of the syntax tree
+func compile(n node) (compiled, error){
+	switch val := n.(type) {
+	case literalNode:
+		return compileLiteral(val)
+	case identNode:
+		return compileIdent(val)
+	case logicalNode:
+		return compileLogical(val)
+	case notNode:
+		operand, err := compileCondition(val.operand)
+		if err != nil {
+			return compiled{}, err
+		}
+		return compiled{kind: kindBool, boolean: func(env *Env) bool { return !operand(env) }}, nil
+	case compareNode:
+		return compileCompare(val)
+	case inNode:
+		return compileIn(val)
+	case betweenNode:
+		return compileBetween(val)
+	}
+	return compiled{}, newError(n.position(), "unexpected expression")
+}
+
+// About compile a node that must be a condition
+func compileCondition(n node) (func(*Env) bool, error){
+	res_compiled, err := compile(n)
+	if err != nil {
+		return nil, err
+	}
+	if res_compiled.kind != kindBool {
+		return nil, newError(n.position(), "expected a condition, found a " + kindName[res_compiled.kind])
+	}
+	return res_compiled.boolean, nil
+}
+
+func compileLiteral(n literalNode) (compiled, error){
+	switch n.kind {
+	case tokenNumber:
+		number, ok := new(big.Rat).SetString(n.text)
+		if !ok {
+			return compiled{}, newError(n.pos, "invalid number '" + n.text + "'")
+		}
+		return compiled{kind: kindNumber, number: func(*Env) *big.Rat { return number }}, nil
+
+	case tokenClock:
+		parts := strings.Split(n.text, ":")
+		hour, err_hour := strconv.Atoi(parts[0])
+		minute, err_minute := strconv.Atoi(parts[1])
+		if err_hour != nil || err_minute != nil || hour > 23 || minute > 59 {
+			return compiled{}, newError(n.pos, "invalid time '" + n.text + "'")
+		}
+		clock := hour * 60 + minute
+		return compiled{kind: kindClock, clock: func(*Env) int { return clock }}, nil
+	}
+
+	text := n.text
+	return compiled{kind: kindText, text: func(*Env) string { return text }}, nil
+}
+
+// About the fields of the check, the names are case sensitive
+var fields = map[string]compiled{
+	"amount": {kind: kindNumber, number: func(env *Env) *big.Rat { return new(big.Rat).SetFrac(big.NewInt(int64(env.Amount)), moneyUnit) }},
+	"quantity": {kind: kindNumber, number: func(env *Env) *big.Rat { return big.NewRat(int64(env.Quantity), 1) }},
+	"hour": {kind: kindNumber, number: func(env *Env) *big.Rat { return big.NewRat(int64(env.Time.Hour()), 1) }},
+	"currency": {kind: kindText, text: func(env *Env) string { return env.Currency }},
+	"mcc": {kind: kindText, text: func(env *Env) string { return env.Mcc }},
+	"key": {kind: kindText, text: func(env *Env) string { return env.Key }},
+	"weekday": {kind: kindText, text: func(env *Env) string { return strings.ToUpper(env.Time.Weekday().String()[:3]) }},
+	"time": {kind: kindClock, clock: func(env *Env) int { return env.Time.Hour() * 60 + env.Time.Minute() }},
+}
+
+// About a field of the check or an attribute (attributes.<name>), an unknown name is an error (ex: a typo)
+func compileIdent(n identNode) (compiled, error){
+	if field, ok := fields[n.name]; ok {
+		return field, nil
+	}
+
+	// a missing attribute is empty
+	if name, ok := strings.CutPrefix(n.name, "attributes."); ok {
+		if name == "" {
+			return compiled{}, newError(n.pos, "invalid attribute '" + n.name + "'")
+		}
+		return compiled{kind: kindText, text: func(env *Env) string { return env.Attributes[name] }}, nil
+	}
+
+	if _, ok := fields[strings.ToLower(n.name)]; ok {
+		return compiled{}, newError(n.pos, "unknown field '" + n.name + "', did you mean " + strings.ToLower(n.name))
+	}
+	return compiled{}, newError(n.pos, "unknown field '" + n.name + "', an attribute is attributes." + n.name)
+}
+
+func compileLogical(n logicalNode) (compiled, error){
+	left, err := compileCondition(n.left)
+	if err != nil {
+		return compiled{}, err
+	}
+	right, err := compileCondition(n.right)
+	if err != nil {
+		return compiled{}, err
+	}
+
+	if n.op == "and" {
+		return compiled{kind: kindBool, boolean: func(env *Env) bool { return left(env) && right(env) }}, nil
+	}
+	return compiled{kind: kindBool, boolean: func(env *Env) bool { return left(env) || right(env) }}, nil
+}
+
+// About compare two values of the same type, -1, 0 or 1
+func comparator(left compiled, right compiled) func(*Env) int {
+	switch left.kind {
+	case kindNumber:
+		return func(env *Env) int { return left.number(env).Cmp(right.number(env)) }
+	case kindClock:
+		return func(env *Env) int {
+			l, r := left.clock(env), right.clock(env)
+			switch {
+			case l < r:
+				return -1
+			case l > r:
+				return 1
+			}
+			return 0
+		}
+	case kindText:
+		return func(env *Env) int { return strings.Compare(left.text(env), right.text(env)) }
+	}
+	return func(env *Env) int {
+		if left.boolean(env) == right.boolean(env) {
+			return 0
+		}
+		return 1
+	}
+}
+
+// About compile both sides of a comparison, they must have the same type
+func compileOperands(nodes ...node) ([]compiled, error){
+	list_compiled := []compiled{}
+	for _, val := range nodes {
+		res_compiled, err := compile(val)
+		if err != nil {
+			return nil, err
+		}
+		if len(list_compiled) > 0 && res_compiled.kind != list_compiled[0].kind {
+			return nil, newError(val.position(), "can not compare a " + kindName[list_compiled[0].kind] + " with a " + kindName[res_compiled.kind])
+		}
+		list_compiled = append(list_compiled, res_compiled)
+	}
+	return list_compiled, nil
+}
+
+func compileCompare(n compareNode) (compiled, error){
+	operands, err := compileOperands(n.left, n.right)
+	if err != nil {
+		return compiled{}, err
+	}
+
+	// a text or a condition is only equal or not
+	kind := operands[0].kind
+	if (kind == kindText || kind == kindBool) && n.op != "=" && n.op != "!=" {
+		return compiled{}, newError(n.pos, "operator " + n.op + " is not allowed on a " + kindName[kind])
+	}
+
+	cmp := comparator(operands[0], operands[1])
+	var test func(int) bool
+	switch n.op {
+	case "=":
+		test = func(c int) bool { return c == 0 }
+	case "!=":
+		test = func(c int) bool { return c != 0 }
+	case "<":
+		test = func(c int) bool { return c < 0 }
+	case "<=":
+		test = func(c int) bool { return c <= 0 }
+	case ">":
+		test = func(c int) bool { return c > 0 }
+	default:
+		test = func(c int) bool { return c >= 0 }
+	}
+
+	return compiled{kind: kindBool, boolean: func(env *Env) bool { return test(cmp(env)) }}, nil
+}
+
+func compileIn(n inNode) (compiled, error){
+	operands, err := compileOperands(append([]node{n.left}, n.list...)...)
+	if err != nil {
+		return compiled{}, err
+	}
+
+	list_cmp := []func(*Env) int{}
+	for _, val := range operands[1:] {
+		list_cmp = append(list_cmp, comparator(operands[0], val))
+	}
+
+	negate := n.negate
+	return compiled{kind: kindBool, boolean: func(env *Env) bool {
+		for _, cmp := range list_cmp {
+			if cmp(env) == 0 {
+				return !negate
+			}
+		}
+		return negate
+	}}, nil
+}
+
+// About between is inclusive, a time range ending before it starts crosses midnight (ex: 22:00 and 06:00)
+func compileBetween(n betweenNode) (compiled, error){
+	operands, err := compileOperands(n.left, n.low, n.high)
+	if err != nil {
+		return compiled{}, err
+	}
+
+	kind := operands[0].kind
+	if kind != kindNumber && kind != kindClock {
+		return compiled{}, newError(n.pos, "between is not allowed on a " + kindName[kind])
+	}
+
+	cmp_low := comparator(operands[0], operands[1])
+	cmp_high := comparator(operands[0], operands[2])
+	cmp_range := comparator(operands[1], operands[2])
+	negate := n.negate
+
+	return compiled{kind: kindBool, boolean: func(env *Env) bool {
+		inside := cmp_low(env) >= 0 && cmp_high(env) <= 0
+		if kind == kindClock && cmp_range(env) > 0 {
+			inside = cmp_low(env) >= 0 || cmp_high(env) <= 0
+		}
+		return inside != negate
+	}}, nil
+}
@@ -0,0 +1,118 @@
+package expression
+
+import(
+	"testing"
+	"time"
+
+	"github.com/go-limit/internal/core/model"
+)
+
+// a check of 150.50 BRL at the mcc 5411, on a saturday at 23:30
+func testEnv() Env {
+	return Env{	Amount: 15050,
+				Quantity: 2,
+				Currency: "BRL",
+				Mcc: "5411",
+				Key: "card-1",
+				Time: time.Date(2026, 10, 17, 23, 30, 0, 0, time.UTC),
+				Attributes: map[string]string{"channel": "ATM", "amount": "x"},
+			}
+}
+
+func TestEval(t *testing.T) {
+	cases := []struct {
+		source	string
+		want	bool
+	}{
+		{"amount > 150", true},
+		{"amount > 150.50", false},
+		{"amount >= 150.5", true},
+		{"amount = 150.50", true},
+		{"quantity between 1 and 2", true},
+		{"quantity not between 1 and 2", false},
+		{"hour = 23", true},
+		{"currency = 'BRL'", true},
+		{"currency != 'BRL'", false},
+		{"mcc in ('5411', '5412')", true},
+		{"mcc not in ('5411', '5412')", false},
+		{"key = 'card-1'", true},
+		{"weekday in ('SAT', 'SUN')", true},
+		{"time > 23:00", true},
+		// a time range ending before it starts crosses midnight
+		{"time between 22:00 and 06:00", true},
+		{"time between 06:00 and 22:00", false},
+		{"time not between 22:00 and 06:00", false},
+		{"attributes.channel = 'ATM'", true},
+		// an attribute named as a field
+		{"attributes.amount = 'x'", true},
+		// a missing attribute is empty
+		{"attributes.country = ''", true},
+		// and binds tighter than or
+		{"amount > 1000 and mcc = '5411' or hour = 23", true},
+		{"amount > 1000 and (mcc = '5411' or hour = 23)", false},
+		{"not amount > 1000 and hour = 23", true},
+		{"not (amount > 100 and hour = 23)", false},
+		{"(amount > 100) = (hour = 23)", true},
+	}
+
+	for _, c := range cases {
+		program, err := Compile(c.source)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", c.source, err)
+			continue
+		}
+		if res := program.Eval(testEnv()); res != c.want {
+			t.Errorf("Eval(%q) = %v, want %v", c.source, res, c.want)
+		}
+	}
+}
+
+// the types are checked when compiled, with the position of the error
+func TestCompileError(t *testing.T) {
+	cases := []struct {
+		source		string
+		position	int
+		message		string
+	}{
+		// an unknown name is rejected, the fields are case sensitive
+		{"channel = 'ATM'", 1, "unknown field 'channel', an attribute is attributes.channel"},
+		{"amount > 1 and amout > 1", 16, "unknown field 'amout', an attribute is attributes.amout"},
+		{"Amount > 1", 1, "unknown field 'Amount', did you mean amount"},
+		{"MCC = '5411'", 1, "unknown field 'MCC', did you mean mcc"},
+		{"attributes. = 'x'", 1, "invalid attribute 'attributes.'"},
+		{"amount", 1, "the expression must be a condition, found a number"},
+		{"amount > 1 and hour", 16, "expected a condition, found a number"},
+		{"amount = 'x'", 10, "can not compare a number with a text"},
+		{"mcc in ('5411', 5412)", 17, "can not compare a text with a number"},
+		{"mcc > '5411'", 5, "operator > is not allowed on a text"},
+		{"mcc between '1' and '2'", 5, "between is not allowed on a text"},
+		{"time > 24:00", 8, "invalid time '24:00'"},
+		{"amount > 1.2.3", 10, "invalid number '1.2.3'"},
+		{"amount >", 9, "expected a value, found end of expression"},
+	}
+
+	for _, c := range cases {
+		_, err := Compile(c.source)
+		res, ok := err.(*Error)
+		if !ok {
+			t.Errorf("Compile(%q): %v, want an error at position %d", c.source, err, c.position)
+			continue
+		}
+		if res.Position != c.position || res.Message != c.message {
+			t.Errorf("Compile(%q): %q at %d, want %q at %d", c.source, res.Message, res.Position, c.message, c.position)
+		}
+	}
+}
+
+// the amount is compared exactly, without going through a float
+func TestEvalAmountExact(t *testing.T) {
+	program, err := Compile("amount = 0.30")
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	env := testEnv()
+	env.Amount = model.Money(10) + model.Money(20)
+	if !program.Eval(env) {
+		t.Errorf("Eval(amount = 0.30) with 0.10 + 0.20 = false, want true")
+	}
+}
@@ -11,6 +11,7 @@ type AppServer struct {
 	Server     		*Server     				`json:"server"`
 	ConfigOTEL		*go_core_observ.ConfigOTEL	`json:"otel_config"`
 	DatabaseConfig	*go_core_pg.DatabaseConfig  `json:"database"`
+	LimitConfig		*LimitConfig				`json:"limit_config"`
 }
 
 type InfoPod struct {
@@ -32,6 +33,22 @@ type Server struct {
 	CtxTimeout		int `json:"ctxTimeout"`
 }
 
+type LimitConfig struct {
+	Storage						string 	`json:"storage"`
+	ReservationSweepInterval	int 	`json:"reservation_sweep_interval"`
+	MigrateOnStart				bool 	`json:"migrate_on_start"`
+	CounterStore				string 	`json:"counter_store,omitempty"`
+	RedisAddress				string 	`json:"redis_address,omitempty"`
+	RedisPassword				string 	`json:"-"`
+	LedgerBuffer				int 	`json:"ledger_buffer,omitempty"`
+	RetentionInterval			int 	`json:"retention_interval"`
+	RetentionGrace				int 	`json:"retention_grace"`
+	RetentionBatchSize			int 	`json:"retention_batch_size"`
+	RetentionArchive			bool 	`json:"retention_archive"`
+	FxRates						string 	`json:"fx_rates,omitempty"`
+	BaseCurrency				string 	`json:"base_currency,omitempty"`
+}
+
 type MessageRouter struct {
 	Message			string `json:"message"`
 }
@@ -39,14 +56,60 @@ type MessageRouter struct {
 type TypeLimit struct {
 	Code			string 		`json:"code,omitempty"`
 	Category		string 		`json:"category,omitempty"`
+	Timezone		string 		`json:"timezone,omitempty"`
+	BillingCycleDay	int 		`json:"billing_cycle_day,omitempty"`
 	CreateAt		time.Time 	`json:"created_at,omitempty"`	
 }
 
+type CounterLimit struct {
+	Code			string 		`json:"code,omitempty"`
+}
+
 type OrderLimit struct {
+	ID				int			`json:"id,omitempty"`
 	TypeLimit		string 		`json:"type_limit,omitempty"`
 	CounterLimit	string 		`json:"counter_limit,omitempty"`
 	Type			string 		`json:"type,omitempty"`
-	Amount			int 		`json:"amount,omitempty"`
+	Amount			Money 		`json:"amount,omitempty"`
+	Currency		string 		`json:"currency,omitempty"`
+	Mcc				string 		`json:"mcc,omitempty"`
+	Conditions		[]LimitCondition `json:"conditions,omitempty"`
+	Expression		string 		`json:"expression,omitempty"`
+	Window			string 		`json:"window,omitempty"`
+	OverrideId		int			`json:"override_id,omitempty"`
+	AdjustmentId	int			`json:"adjustment_id,omitempty"`
+	CreateAt		time.Time 	`json:"created_at,omitempty"`	
+}
+
+type LimitCondition struct {
+	Attribute		string 		`json:"attribute,omitempty"`
+	Operator		string 		`json:"operator,omitempty"`
+	Values			[]string 	`json:"values,omitempty"`
+}
+
+type MccGroup struct {
+	Code			string 		`json:"code,omitempty"`
+	Mccs			[]string 	`json:"mccs"`
+	CreateAt		time.Time 	`json:"created_at,omitempty"`	
+}
+
+type LimitAdjustment struct {
+	ID				int			`json:"id,omitempty"`
+	Key				string 		`json:"key,omitempty"`
+	OrderLimitId	int			`json:"order_limit_id,omitempty"`
+	Type			string 		`json:"type,omitempty"`
+	Value			Money 		`json:"value"`
+	StartAt			time.Time 	`json:"starts_at"`
+	EndAt			time.Time 	`json:"ends_at"`
+	CreateAt		time.Time 	`json:"created_at,omitempty"`	
+}
+
+type LimitOverride struct {
+	ID				int			`json:"id,omitempty"`
+	Key				string 		`json:"key,omitempty"`
+	OrderLimitId	int			`json:"order_limit_id,omitempty"`
+	Amount			Money 		`json:"amount"`
+	ExpireAt		*time.Time 	`json:"expires_at,omitempty"`
 	CreateAt		time.Time 	`json:"created_at,omitempty"`	
 }
 
@@ -56,8 +119,19 @@ type Limit struct {
 	TypeLimit		string 		`json:"type_limit,omitempty"`
 	OrderLimit		string 		`json:"order_limit,omitempty"`
 	CounterLimit	string 		`json:"counter_limit,omitempty"`	
-	Amount			float64 	`json:"amount,omitempty"`
+	Amount			Money 		`json:"amount,omitempty"`
+	Currency		string 		`json:"currency,omitempty"`
+	Mcc				string 		`json:"mcc,omitempty"`
+	Attributes		map[string]string `json:"attributes,omitempty"`
 	Quantity		int 		`json:"quantity,omitempty"`
+	EvaluationMode	string 		`json:"evaluation_mode,omitempty"`
+	ReservationTtl	int 		`json:"reservation_ttl,omitempty"`
+	Levels			[]LimitLevel `json:"levels,omitempty"`
+}
+
+type LimitLevel struct {
+	OrderLimit		string 		`json:"order_limit,omitempty"`
+	Key				string 		`json:"key,omitempty"`
 }
 
 type LimitTransaction struct {
@@ -68,6 +142,122 @@ type LimitTransaction struct {
 	CounterLimit	string 		`json:"counter_limit,omitempty"`	
 	OrderLimit		string 		`json:"order_limit,omitempty"`	
 	Status			string 		`json:"status,omitempty"`
-	Amount			float64 	`json:"amount,omitempty"`
+	Amount			Money 		`json:"amount,omitempty"`
+	Currency		string 		`json:"currency,omitempty"`
+	Mcc				string 		`json:"mcc,omitempty"`
+	OriginalAmount	Money 		`json:"original_amount,omitempty"`
+	OriginalCurrency	string 	`json:"original_currency,omitempty"`
+	FxRate			string 		`json:"fx_rate,omitempty"`
+	AdjustmentId	int			`json:"adjustment_id,omitempty"`
+	RuleId			int			`json:"rule_id,omitempty"`
+	Window			string 		`json:"window,omitempty"`
+	LimitAmount		Money 		`json:"limit_amount"`
+	Consumed		Money 		`json:"consumed"`
+	Remaining		Money 		`json:"remaining"`
+	ResetAt			*time.Time 	`json:"reset_at,omitempty"`
+	ReversedAmount	Money 		`json:"reversed_amount,omitempty"`
+	ReversedAt		*time.Time 	`json:"reversed_at,omitempty"`
+	ReferenceId		int 		`json:"reference_id,omitempty"`
+	ExpireAt		*time.Time 	`json:"expires_at,omitempty"`
 	CreareAt		time.Time 	`json:"created_at,omitempty"`			
 }
+
+type LimitReversal struct {
+	TransactionId	string 		`json:"transaction_id,omitempty"`
+	Amount			Money 		`json:"amount,omitempty"`
+	Quantity		int 		`json:"quantity,omitempty"`
+}
+
+type LimitScope struct {
+	Mccs			[]string 	`json:"mccs,omitempty"`
+	RuleId			int			`json:"rule_id,omitempty"`
+	Window			string 		`json:"window,omitempty"`
+}
+
+type LimitUsage struct {
+	Amount			Money 		`json:"amount"`
+	Quantity		int 		`json:"quantity"`
+	FirstCreateAt	*time.Time 	`json:"first_created_at,omitempty"`
+}
+
+type LimitRequest struct {
+	TransactionId	string 			`json:"transaction_id,omitempty"`
+	PayloadHash		string 			`json:"payload_hash,omitempty"`
+	Response		*LimitDecision 	`json:"response,omitempty"`
+	CreateAt		time.Time 		`json:"created_at,omitempty"`
+}
+
+type LimitDecision struct {
+	TransactionId		string 				`json:"transaction_id,omitempty"`
+	Decision			string 				`json:"decision"`
+	BreachOrderLimit	*OrderLimit 		`json:"breach_order_limit,omitempty"`
+	LimitTransactions	[]LimitTransaction 	`json:"limit_transactions"`
+}
+
+type LimitCounter struct {
+	TransactionId	string 			`json:"transaction_id,omitempty"`
+	Key				string 			`json:"key,omitempty"`
+	PayloadHash		string 			`json:"payload_hash,omitempty"`
+	Replay			bool 			`json:"replay,omitempty"`
+	ReplayHash		string 			`json:"replay_hash,omitempty"`
+	Simulate		bool 			`json:"simulate,omitempty"`
+	Windows			[]LimitWindow 	`json:"windows,omitempty"`
+}
+
+type LimitWindow struct {
+	Key				string 		`json:"key,omitempty"`
+	TypeLimit		string 		`json:"type_limit,omitempty"`
+	OrderLimit		string 		`json:"order_limit,omitempty"`
+	CounterLimit	string 		`json:"counter_limit,omitempty"`
+	Member			string 		`json:"member,omitempty"`
+	WindowStart		time.Time 	`json:"window_start,omitempty"`
+	WindowEnd		*time.Time 	`json:"window_end,omitempty"`
+	Mcc				string 		`json:"mcc,omitempty"`
+	Mccs			[]string 	`json:"mccs,omitempty"`
+	RuleId			int			`json:"rule_id,omitempty"`
+	Window			string 		`json:"window,omitempty"`
+	Amount			Money 		`json:"amount,omitempty"`
+	LimitAmount		Money 		`json:"limit_amount,omitempty"`
+	ByQuantity		bool 		`json:"by_quantity,omitempty"`
+	EvaluationMode	string 		`json:"evaluation_mode,omitempty"`
+	ExpireAt		*time.Time 	`json:"expires_at,omitempty"`
+	CreateAt		time.Time 	`json:"created_at,omitempty"`
+	Usage			LimitUsage 	`json:"usage"`
+	Applied			bool 		`json:"applied"`
+}
+
+type PoolStats struct {
+	AcquireCount			int64 	`json:"acquire_count"`
+	AcquiredConns			int32 	`json:"acquired_conns"`
+	CanceledAcquireCount	int64 	`json:"canceled_acquire_count"`
+	ConstructingConns		int32 	`json:"constructing_conns"`
+	EmptyAcquireCount		int64 	`json:"empty_acquire_count"`
+	IdleConns				int32 	`json:"idle_conns"`
+	MaxConns				int32 	`json:"max_conns"`
+	TotalConns				int32 	`json:"total_conns"`
+}
+
+type LimitStat struct {
+	PoolStats
+	Retention		*RetentionStat 	`json:"retention,omitempty"`
+}
+
+type RetentionStat struct {
+	Enabled			bool 		`json:"enabled"`
+	Archive			bool 		`json:"archive"`
+	Running			bool 		`json:"running"`
+	LastRunAt		*time.Time 	`json:"last_run_at,omitempty"`
+	LastCutoff		*time.Time 	`json:"last_cutoff,omitempty"`
+	LastPurged		int64 		`json:"last_purged"`
+	LastDuration	float64 	`json:"last_duration_ms"`
+	LastError		string 		`json:"last_error,omitempty"`
+	TotalPurged		int64 		`json:"total_purged"`
+	TotalRuns		int64 		`json:"total_runs"`
+}
+
+type FxRate struct {
+	From			string 		`json:"from,omitempty"`
+	To				string 		`json:"to,omitempty"`
+	Rate			string 		`json:"rate,omitempty"`
+	Source			string 		`json:"source,omitempty"`
+}
@@ -0,0 +1,164 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"math/big"
+	"database/sql/driver"
+
+	"github.com/go-limit/internal/core/erro"
+)
+
+// Money is an exact amount in minor units, with a fixed scale of MoneyScale fractional digits
+// It is stored as numeric(18,2) and it is a json number (ex: 10.50)
+// An amount with more fractional digits than the scale is rejected, never rounded
+// The operations that need rounding (ex: a percentage, a rate) round half to even
+type Money int64
+
+// Above the number of fractional digits of Money
+const MoneyScale = 2
+
+// Above the number of minor units of a major unit
+const moneyUnit = 100
+
+// Above the money of an integer number of major units (ex: a quantity)
+func MoneyFromInt(value int64) Money {
+	return Money(value * moneyUnit)
+}
+
+// Above parse an exact decimal (ex: 10, -10.5, 10.05)
+func ParseMoney(value string) (Money, error) {
+	value = strings.TrimSpace(value)
+
+	// a single sign (ex: -+5 is rejected by the digits below)
+	negative := strings.HasPrefix(value, "-")
+	if negative || strings.HasPrefix(value, "+") {
+		value = value[1:]
+	}
+
+	integer, fraction, _ := strings.Cut(value, ".")
+	if integer == "" && fraction == "" {
+		return 0, erro.ErrInvalidAmount
+	}
+	if integer == "" {
+		integer = "0"
+	}
+
+	// the zeros on the right do not change the amount
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > MoneyScale {
+		return 0, erro.ErrInvalidAmount
+	}
+	fraction = fraction + strings.Repeat("0", MoneyScale - len(fraction))
+
+	for _, digit := range integer + fraction {
+		if digit < '0' || digit > '9' {
+			return 0, erro.ErrInvalidAmount
+		}
+	}
+
+	minor, err := strconv.ParseInt(integer + fraction, 10, 64)
+	if err != nil {
+		return 0, erro.ErrInvalidAmount
+	}
+	if negative {
+		minor = -minor
+	}
+
+	return Money(minor), nil
+}
+
+// Above the exact decimal, always with MoneyScale fractional digits (ex: 10.50)
+func (m Money) String() string {
+	minor := int64(m)
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, minor / moneyUnit, MoneyScale, minor % moneyUnit)
+}
+
+// Above the integer number of major units, the fraction is dropped (ex: a quantity)
+func (m Money) Int() int64 {
+	return int64(m) / moneyUnit
+}
+
+// Above multiply by a ratio (numerator / denominator), rounding half to even
+func (m Money) MulRat(ratio *big.Rat) Money {
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(m)), ratio)
+	return Money(roundHalfEven(value).Int64())
+}
+
+// Above round a rational to an integer, half to even
+func roundHalfEven(value *big.Rat) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(value.Num(), value.Denom(), new(big.Int))
+
+	// compare twice the remainder with the denominator
+	twice := new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2))
+	switch twice.Cmp(value.Denom()) {
+	case 1:
+		quotient.Add(quotient, big.NewInt(int64(value.Sign())))
+	case 0:
+		if quotient.Bit(0) == 1 {
+			quotient.Add(quotient, big.NewInt(int64(value.Sign())))
+		}
+	}
+
+	return quotient
+}
+
+// Above the json number (ex: 10.50)
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// Above accept a json number or a string, without going through a float
+func (m *Money) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "null" {
+		return nil
+	}
+
+	res, err := ParseMoney(value)
+	if err != nil {
+		return err
+	}
+	*m = res
+
+	return nil
+}
+
+// Above the value written on the database (numeric)
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Above read the value of the database (numeric, integer)
+func (m *Money) Scan(src any) error {
+	switch value := src.(type) {
+	case nil:
+		*m = 0
+		return nil
+	case int64:
+		*m = MoneyFromInt(value)
+		return nil
+	case string:
+		res, err := ParseMoney(value)
+		if err != nil {
+			return err
+		}
+		*m = res
+		return nil
+	case []byte:
+		res, err := ParseMoney(string(value))
+		if err != nil {
+			return err
+		}
+		*m = res
+		return nil
+	}
+
+	return fmt.Errorf("cannot scan %T into Money", src)
+}
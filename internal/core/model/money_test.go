@@ -0,0 +1,215 @@
+package model
+
+import(
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/go-limit/internal/core/erro"
+)
+
+func TestParseMoney(t *testing.T) {
+	cases := []struct {
+		value	string
+		want	Money
+		err		error
+	}{
+		{"10", 1000, nil},
+		{"10.5", 1050, nil},
+		{"10.05", 1005, nil},
+		{" 10.50 ", 1050, nil},
+		{".5", 50, nil},
+		{"5.", 500, nil},
+		{"+5", 500, nil},
+		{"-10.5", -1050, nil},
+		{"-0.05", -5, nil},
+		{"-0", 0, nil},
+		// the zeros on the right do not change the amount
+		{"10.500", 1050, nil},
+		{"92233720368547758.07", Money(9223372036854775807), nil},
+		{"-92233720368547758.07", Money(-9223372036854775807), nil},
+		// more fractional digits than the scale are never rounded
+		{"10.005", 0, erro.ErrInvalidAmount},
+		{"-0.001", 0, erro.ErrInvalidAmount},
+		// out of the range, symmetric (the minimum of int64 is not an amount)
+		{"92233720368547758.08", 0, erro.ErrInvalidAmount},
+		{"-92233720368547758.08", 0, erro.ErrInvalidAmount},
+		{"100000000000000000000", 0, erro.ErrInvalidAmount},
+		// an exponent is not an exact decimal
+		{"1e2", 0, erro.ErrInvalidAmount},
+		{"1.5E+2", 0, erro.ErrInvalidAmount},
+		{"", 0, erro.ErrInvalidAmount},
+		{".", 0, erro.ErrInvalidAmount},
+		{"-", 0, erro.ErrInvalidAmount},
+		{"-+5", 0, erro.ErrInvalidAmount},
+		{"--5", 0, erro.ErrInvalidAmount},
+		{"1,5", 0, erro.ErrInvalidAmount},
+		{"1.2.3", 0, erro.ErrInvalidAmount},
+		{"abc", 0, erro.ErrInvalidAmount},
+		{"null", 0, erro.ErrInvalidAmount},
+	}
+
+	for _, c := range cases {
+		res, err := ParseMoney(c.value)
+		if err != c.err {
+			t.Errorf("ParseMoney(%q): error %v, want %v", c.value, err, c.err)
+			continue
+		}
+		if res != c.want {
+			t.Errorf("ParseMoney(%q) = %d, want %d", c.value, res, c.want)
+		}
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	cases := []struct {
+		value	Money
+		want	string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{1050, "10.50"},
+		{-5, "-0.05"},
+		{-1050, "-10.50"},
+		{MoneyFromInt(3), "3.00"},
+	}
+
+	for _, c := range cases {
+		if res := c.value.String(); res != c.want {
+			t.Errorf("Money(%d).String() = %s, want %s", c.value, res, c.want)
+		}
+		// the string is parsed back to the same amount
+		res, err := ParseMoney(c.want)
+		if err != nil || res != c.value {
+			t.Errorf("ParseMoney(%s) = %d, %v, want %d", c.want, res, err, c.value)
+		}
+	}
+}
+
+func TestMoneyInt(t *testing.T) {
+	cases := []struct {
+		value	Money
+		want	int64
+	}{
+		{1099, 10},
+		{100, 1},
+		{99, 0},
+		{-1099, -10},
+	}
+
+	for _, c := range cases {
+		if res := c.value.Int(); res != c.want {
+			t.Errorf("Money(%d).Int() = %d, want %d", c.value, res, c.want)
+		}
+	}
+}
+
+// the rounding is half to even, with the same result for a negative amount
+func TestMoneyMulRat(t *testing.T) {
+	cases := []struct {
+		value	Money
+		ratio	*big.Rat
+		want	Money
+	}{
+		{1000, big.NewRat(1, 2), 500},
+		{1, big.NewRat(1, 2), 0},
+		{3, big.NewRat(1, 2), 2},
+		{5, big.NewRat(1, 2), 2},
+		{-3, big.NewRat(1, 2), -2},
+		{-5, big.NewRat(1, 2), -2},
+		{1000, big.NewRat(1, 3), 333},
+		{2000, big.NewRat(1, 3), 667},
+		{-2000, big.NewRat(1, 3), -667},
+	}
+
+	for _, c := range cases {
+		if res := c.value.MulRat(c.ratio); res != c.want {
+			t.Errorf("Money(%d).MulRat(%s) = %d, want %d", c.value, c.ratio, res, c.want)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	type payload struct {
+		Amount	Money	`json:"amount"`
+	}
+
+	res, err := json.Marshal(payload{Amount: -1050})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(res) != `{"amount":-10.50}` {
+		t.Errorf("Marshal = %s, want a json number", res)
+	}
+
+	cases := []struct {
+		data	string
+		want	Money
+		err		bool
+	}{
+		{`{"amount":10.5}`, 1050, false},
+		{`{"amount":"10.5"}`, 1050, false},
+		{`{"amount":-0.05}`, -5, false},
+		{`{"amount":10.50}`, 1050, false},
+		// null keeps the amount as it was
+		{`{"amount":null}`, 7, false},
+		{`{}`, 7, false},
+		{`{"amount":10.005}`, 0, true},
+		{`{"amount":1e2}`, 0, true},
+		{`{"amount":92233720368547758.08}`, 0, true},
+		{`{"amount":true}`, 0, true},
+	}
+
+	for _, c := range cases {
+		res := payload{Amount: 7}
+		err := json.Unmarshal([]byte(c.data), &res)
+		if (err != nil) != c.err {
+			t.Errorf("Unmarshal(%s): error %v, want error %v", c.data, err, c.err)
+			continue
+		}
+		if !c.err && res.Amount != c.want {
+			t.Errorf("Unmarshal(%s) = %d, want %d", c.data, res.Amount, c.want)
+		}
+	}
+}
+
+func TestMoneyValueScan(t *testing.T) {
+	value, err := Money(-1050).Value()
+	if err != nil || value != "-10.50" {
+		t.Errorf("Value = %v, %v, want -10.50", value, err)
+	}
+
+	cases := []struct {
+		src		any
+		want	Money
+		err		bool
+	}{
+		{nil, 0, false},
+		{int64(10), 1000, false},
+		{int64(-10), -1000, false},
+		{"10.50", 1050, false},
+		{[]byte("-0.05"), -5, false},
+		{"10.005", 0, true},
+		{[]byte("1e2"), 0, true},
+		{float64(10.5), 0, true},
+		{true, 0, true},
+	}
+
+	for _, c := range cases {
+		res := Money(7)
+		err := res.Scan(c.src)
+		if (err != nil) != c.err {
+			t.Errorf("Scan(%v): error %v, want error %v", c.src, err, c.err)
+			continue
+		}
+		if !c.err && res != c.want {
+			t.Errorf("Scan(%v) = %d, want %d", c.src, res, c.want)
+		}
+	}
+
+	// the value written is scanned back to the same amount
+	var res Money
+	if err := res.Scan(value); err != nil || res != -1050 {
+		t.Errorf("Scan(Value) = %d, %v, want -1050", res, err)
+	}
+}
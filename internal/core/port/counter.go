@@ -0,0 +1,19 @@
+package port
+
+import(
+	"context"
+
+	"github.com/go-limit/internal/core/model"
+)
+
+// About the hot-path store of the window counters, the storage stays the durable ledger
+// All windows of a check are evaluated and consumed atomically, per key
+type CounterStore interface {
+	ConsumeLimitCounter(ctx context.Context, limitCounter model.LimitCounter) (*model.LimitCounter, error)
+	AddLimitWindow(ctx context.Context, key string, limitWindow model.LimitWindow) error
+	RemoveLimitWindow(ctx context.Context, key string, limitWindow model.LimitWindow) error
+	ConfirmLimitWindow(ctx context.Context, key string, limitWindow model.LimitWindow) error
+
+	GetLimitDecision(ctx context.Context, transactionId string) (*model.LimitDecision, error)
+	AddLimitDecision(ctx context.Context, limitDecision model.LimitDecision) error
+}
@@ -0,0 +1,13 @@
+package port
+
+import(
+	"context"
+
+	"github.com/go-limit/internal/core/model"
+)
+
+// About the provider of the fx rates, used to convert an amount to the currency of a limit
+// The rate is an exact decimal (ex: 5.4321), 1 unit of From is Rate units of To
+type FxProvider interface {
+	GetFxRate(ctx context.Context, fxRate model.FxRate) (*model.FxRate, error)
+}
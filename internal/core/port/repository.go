@@ -0,0 +1,70 @@
+package port
+
+import(
+	"time"
+	"context"
+
+	"github.com/go-limit/internal/core/model"
+)
+
+// About a transaction opened by the storage
+// Commit and Rollback end the transaction and give back any resource held by it
+type Tx interface {
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+// About the storage port used by the limit engine, each storage backend is an adapter
+type WorkerRepository interface {
+	Stat(ctx context.Context) (model.PoolStats)
+	StartTx(ctx context.Context) (Tx, error)
+	LockKey(ctx context.Context, tx Tx, key string) error
+
+	GetTypeLimit(ctx context.Context, typeLimit model.TypeLimit) (*model.TypeLimit, error)
+	ListTypeLimit(ctx context.Context) (*[]model.TypeLimit, error)
+	AddTypeLimit(ctx context.Context, typeLimit model.TypeLimit) (*model.TypeLimit, error)
+	UpdateTypeLimit(ctx context.Context, typeLimit model.TypeLimit) (int64, error)
+	DeleteTypeLimit(ctx context.Context, typeLimit model.TypeLimit) (int64, error)
+
+	GetCounterLimit(ctx context.Context, counterLimit model.CounterLimit) (*model.CounterLimit, error)
+
+	GetOrderLimit(ctx context.Context, orderLimit model.OrderLimit) (*[]model.OrderLimit, error)
+	GetOrderLimitById(ctx context.Context, orderLimit model.OrderLimit) (*model.OrderLimit, error)
+	ListOrderLimit(ctx context.Context, orderLimit model.OrderLimit) (*[]model.OrderLimit, error)
+	AddOrderLimit(ctx context.Context, orderLimit model.OrderLimit) (*model.OrderLimit, error)
+	UpdateOrderLimit(ctx context.Context, orderLimit model.OrderLimit) (int64, error)
+	DeleteOrderLimit(ctx context.Context, orderLimit model.OrderLimit) (int64, error)
+
+	GetMccGroup(ctx context.Context, mccGroup model.MccGroup) (*model.MccGroup, error)
+	ListMccGroup(ctx context.Context) (*[]model.MccGroup, error)
+	AddMccGroup(ctx context.Context, mccGroup model.MccGroup) (*model.MccGroup, error)
+	UpdateMccGroup(ctx context.Context, mccGroup model.MccGroup) (int64, error)
+	DeleteMccGroup(ctx context.Context, mccGroup model.MccGroup) (int64, error)
+
+	GetLimitOverride(ctx context.Context, limitOverride model.LimitOverride) (*[]model.LimitOverride, error)
+	GetLimitOverrideById(ctx context.Context, limitOverride model.LimitOverride) (*model.LimitOverride, error)
+	ListLimitOverride(ctx context.Context, limitOverride model.LimitOverride) (*[]model.LimitOverride, error)
+	AddLimitOverride(ctx context.Context, limitOverride model.LimitOverride) (*model.LimitOverride, error)
+	UpdateLimitOverride(ctx context.Context, limitOverride model.LimitOverride) (int64, error)
+	DeleteLimitOverride(ctx context.Context, limitOverride model.LimitOverride) (int64, error)
+
+	GetLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) (*[]model.LimitAdjustment, error)
+	GetLimitAdjustmentById(ctx context.Context, limitAdjustment model.LimitAdjustment) (*model.LimitAdjustment, error)
+	ListLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) (*[]model.LimitAdjustment, error)
+	AddLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) (*model.LimitAdjustment, error)
+	UpdateLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) (int64, error)
+	DeleteLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) (int64, error)
+
+	GetLimitTransactionPerKey(ctx context.Context, tx Tx, limit model.Limit, scope model.LimitScope, windowStart time.Time) (*model.LimitUsage, error)
+	AddLimitTransaction(ctx context.Context, tx Tx, limitTransaction model.LimitTransaction) (*model.LimitTransaction, error)
+	GetLimitTransactionByTransactionId(ctx context.Context, tx Tx, limitTransaction model.LimitTransaction) (*[]model.LimitTransaction, error)
+	UpdateLimitTransactionReversal(ctx context.Context, tx Tx, limitTransaction model.LimitTransaction) (int64, error)
+	UpdateLimitTransactionStatus(ctx context.Context, tx Tx, limitTransaction model.LimitTransaction) (int64, error)
+	ExpireLimitReservation(ctx context.Context) (int64, error)
+	PurgeLimitTransaction(ctx context.Context, before time.Time, batchSize int, archive bool) (int64, error)
+	PurgeCounterBucket(ctx context.Context, before time.Time, batchSize int) (int64, error)
+
+	GetLimitRequest(ctx context.Context, tx Tx, limitRequest model.LimitRequest) (*model.LimitRequest, error)
+	AddLimitRequest(ctx context.Context, tx Tx, limitRequest model.LimitRequest) (*model.LimitRequest, error)
+	PurgeLimitRequest(ctx context.Context, before time.Time, batchSize int) (int64, error)
+}
@@ -0,0 +1,194 @@
+package service
+
+import(
+	"time"
+	"context"
+	"math/big"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/erro"
+)
+
+// About the types of an adjustment
+// ABSOLUTE adds the value to the amount of the order limit, PERCENTAGE adds a percentage of it (ex: 50.00 is +50%)
+const (
+	adjustmentAbsolute   = "ABSOLUTE"
+	adjustmentPercentage = "PERCENTAGE"
+)
+
+// About validate an adjustment, the order limit must exist and the adjustment must end in the future
+func (s *WorkerService) validateLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) error {
+	if limitAdjustment.Key == "" || limitAdjustment.OrderLimitId <= 0 || limitAdjustment.Value == 0 {
+		return erro.ErrBadRequest
+	}
+
+	switch limitAdjustment.Type {
+	case adjustmentAbsolute:
+	case adjustmentPercentage:
+		// a percentage can not take more than the whole amount
+		if limitAdjustment.Value < model.MoneyFromInt(-100) {
+			return erro.ErrBadRequest
+		}
+	default:
+		return erro.ErrBadRequest
+	}
+
+	if limitAdjustment.StartAt.IsZero() ||
+		!limitAdjustment.EndAt.After(limitAdjustment.StartAt) ||
+		!limitAdjustment.EndAt.After(time.Now()) {
+		return erro.ErrBadRequest
+	}
+
+	_, err := s.workerRepository.GetOrderLimitById(ctx, model.OrderLimit{ID: limitAdjustment.OrderLimitId})
+	if err == erro.ErrNotFound {
+		return erro.ErrBadRequest
+	}
+	if err != nil {
+		return err
+	}
+
+	// only one adjustment is in force at a time, so the schedules of a key and order limit can not overlap
+	res_list_limit_adjustment, err := s.workerRepository.ListLimitAdjustment(ctx, model.LimitAdjustment{Key: limitAdjustment.Key})
+	if err != nil {
+		return err
+	}
+	for _, val := range *res_list_limit_adjustment {
+		if val.ID != limitAdjustment.ID &&
+			val.OrderLimitId == limitAdjustment.OrderLimitId &&
+			val.StartAt.Before(limitAdjustment.EndAt) &&
+			limitAdjustment.StartAt.Before(val.EndAt) {
+			return erro.ErrConflict
+		}
+	}
+
+	return nil
+}
+
+// About the amount of an order limit with an adjustment, never below zero
+// A percentage is rounded half to even
+func adjustAmount(amount model.Money, limitAdjustment model.LimitAdjustment) model.Money {
+	var adjusted model.Money
+
+	switch limitAdjustment.Type {
+	case adjustmentAbsolute:
+		adjusted = amount + limitAdjustment.Value
+	case adjustmentPercentage:
+		// the value is in minor units, so 100% is 10000
+		percent := model.MoneyFromInt(100)
+		adjusted = amount.MulRat(big.NewRat(int64(percent + limitAdjustment.Value), int64(percent)))
+	default:
+		adjusted = amount
+	}
+
+	if adjusted < 0 {
+		adjusted = 0
+	}
+	return adjusted
+}
+
+// About apply the adjustments of a key active now to its order limits
+func (s *WorkerService) adjustOrderLimit(ctx context.Context, key string, listOrderLimit []model.OrderLimit) ([]model.OrderLimit, error){
+	res_list_limit_adjustment, err := s.workerRepository.GetLimitAdjustment(ctx, model.LimitAdjustment{Key: key})
+	if err != nil {
+		return nil, err
+	}
+	if len(*res_list_limit_adjustment) == 0 {
+		return listOrderLimit, nil
+	}
+
+	limit_adjustment := map[int]model.LimitAdjustment{}
+	for _, val := range *res_list_limit_adjustment {
+		limit_adjustment[val.OrderLimitId] = val
+	}
+
+	list_order_limit := []model.OrderLimit{}
+	for _, val := range listOrderLimit {
+		if adjustment, ok := limit_adjustment[val.ID]; ok {
+			val.Amount = adjustAmount(val.Amount, adjustment)
+			val.AdjustmentId = adjustment.ID
+		}
+		list_order_limit = append(list_order_limit, val)
+	}
+
+	return list_order_limit, nil
+}
+
+// About list the adjustments (optionally of a key)
+func (s *WorkerService) ListLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) (*[]model.LimitAdjustment, error){
+	childLogger.Info().Str("func","ListLimitAdjustment").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.ListLimitAdjustment")
+	defer span.End()
+
+	return s.workerRepository.ListLimitAdjustment(ctx, limitAdjustment)
+}
+
+// About get an adjustment
+func (s *WorkerService) GetLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) (*model.LimitAdjustment, error){
+	childLogger.Info().Str("func","GetLimitAdjustment").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.GetLimitAdjustment")
+	defer span.End()
+
+	return s.workerRepository.GetLimitAdjustmentById(ctx, limitAdjustment)
+}
+
+// About schedule an adjustment
+func (s *WorkerService) AddLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) (*model.LimitAdjustment, error){
+	childLogger.Info().Str("func","AddLimitAdjustment").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("limitAdjustment", limitAdjustment).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.AddLimitAdjustment")
+	defer span.End()
+
+	limitAdjustment.ID = 0
+	if err := s.validateLimitAdjustment(ctx, limitAdjustment); err != nil {
+		return nil, err
+	}
+
+	return s.workerRepository.AddLimitAdjustment(ctx, limitAdjustment)
+}
+
+// About update an adjustment
+func (s *WorkerService) UpdateLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) (*model.LimitAdjustment, error){
+	childLogger.Info().Str("func","UpdateLimitAdjustment").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("limitAdjustment", limitAdjustment).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.UpdateLimitAdjustment")
+	defer span.End()
+
+	if err := s.validateLimitAdjustment(ctx, limitAdjustment); err != nil {
+		return nil, err
+	}
+
+	res, err := s.workerRepository.UpdateLimitAdjustment(ctx, limitAdjustment)
+	if err != nil {
+		return nil, err
+	}
+	if res == 0 {
+		return nil, erro.ErrNotFound
+	}
+
+	return s.workerRepository.GetLimitAdjustmentById(ctx, limitAdjustment)
+}
+
+// About delete an adjustment, the limit transactions keep its id
+func (s *WorkerService) DeleteLimitAdjustment(ctx context.Context, limitAdjustment model.LimitAdjustment) error {
+	childLogger.Info().Str("func","DeleteLimitAdjustment").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("limitAdjustment", limitAdjustment).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.DeleteLimitAdjustment")
+	defer span.End()
+
+	res, err := s.workerRepository.DeleteLimitAdjustment(ctx, limitAdjustment)
+	if err != nil {
+		return err
+	}
+	if res == 0 {
+		return erro.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package service
+
+import(
+	"context"
+	"time"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/erro"
+)
+
+// About validate a type limit, the timezone must be known and the billing cycle day a day of a month
+func validateTypeLimit(typeLimit model.TypeLimit) error {
+	if typeLimit.Code == "" || typeLimit.Category == "" {
+		return erro.ErrBadRequest
+	}
+	if typeLimit.BillingCycleDay < 0 || typeLimit.BillingCycleDay > 31 {
+		return erro.ErrBadRequest
+	}
+	if _, err := typeLimitLocation(typeLimit); err != nil {
+		return err
+	}
+	return nil
+}
+
+// About validate an order limit, the type limit and the counter limit must exist
+func (s *WorkerService) validateOrderLimit(ctx context.Context, orderLimit model.OrderLimit) error {
+	if orderLimit.TypeLimit == "" || orderLimit.CounterLimit == "" || orderLimit.Type == "" || orderLimit.Amount < 0 {
+		return erro.ErrBadRequest
+	}
+
+	if err := validateCurrency(orderLimit.Currency); err != nil {
+		return err
+	}
+
+	if err := s.validateOrderLimitMcc(ctx, orderLimit.Mcc); err != nil {
+		return err
+	}
+
+	if err := validateConditions(orderLimit.Conditions); err != nil {
+		return err
+	}
+
+	// the expression is compiled when it is saved, so a check never finds an invalid one
+	if orderLimit.Expression != "" {
+		if _, err := compileExpression(orderLimit.Expression); err != nil {
+			return err
+		}
+	}
+
+	res_type_limit, err := s.workerRepository.GetTypeLimit(ctx, model.TypeLimit{Code: orderLimit.TypeLimit})
+	if err == erro.ErrNotFound {
+		return erro.ErrBadRequest
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := orderLimitWindow(*res_type_limit, orderLimit, time.Now()); err != nil {
+		return erro.ErrBadRequest
+	}
+
+	_, err = s.workerRepository.GetCounterLimit(ctx, model.CounterLimit{Code: orderLimit.CounterLimit})
+	if err == erro.ErrNotFound {
+		return erro.ErrBadRequest
+	}
+	return err
+}
+
+// About list all type limit
+func (s *WorkerService) ListTypeLimit(ctx context.Context) (*[]model.TypeLimit, error){
+	childLogger.Info().Str("func","ListTypeLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.ListTypeLimit")
+	defer span.End()
+
+	return s.workerRepository.ListTypeLimit(ctx)
+}
+
+// About get a type limit
+func (s *WorkerService) GetTypeLimit(ctx context.Context, typeLimit model.TypeLimit) (*model.TypeLimit, error){
+	childLogger.Info().Str("func","GetTypeLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.GetTypeLimit")
+	defer span.End()
+
+	return s.workerRepository.GetTypeLimit(ctx, typeLimit)
+}
+
+// About add a type limit
+func (s *WorkerService) AddTypeLimit(ctx context.Context, typeLimit model.TypeLimit) (*model.TypeLimit, error){
+	childLogger.Info().Str("func","AddTypeLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("typeLimit", typeLimit).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.AddTypeLimit")
+	defer span.End()
+
+	if err := validateTypeLimit(typeLimit); err != nil {
+		return nil, err
+	}
+
+	return s.workerRepository.AddTypeLimit(ctx, typeLimit)
+}
+
+// About update a type limit
+func (s *WorkerService) UpdateTypeLimit(ctx context.Context, typeLimit model.TypeLimit) (*model.TypeLimit, error){
+	childLogger.Info().Str("func","UpdateTypeLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("typeLimit", typeLimit).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.UpdateTypeLimit")
+	defer span.End()
+
+	if err := validateTypeLimit(typeLimit); err != nil {
+		return nil, err
+	}
+
+	res, err := s.workerRepository.UpdateTypeLimit(ctx, typeLimit)
+	if err != nil {
+		return nil, err
+	}
+	if res == 0 {
+		return nil, erro.ErrNotFound
+	}
+
+	return s.workerRepository.GetTypeLimit(ctx, typeLimit)
+}
+
+// About delete a type limit
+func (s *WorkerService) DeleteTypeLimit(ctx context.Context, typeLimit model.TypeLimit) error {
+	childLogger.Info().Str("func","DeleteTypeLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("typeLimit", typeLimit).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.DeleteTypeLimit")
+	defer span.End()
+
+	res, err := s.workerRepository.DeleteTypeLimit(ctx, typeLimit)
+	if err != nil {
+		return err
+	}
+	if res == 0 {
+		return erro.ErrNotFound
+	}
+
+	return nil
+}
+
+// About list the order limits (optionally of a type limit)
+func (s *WorkerService) ListOrderLimit(ctx context.Context, orderLimit model.OrderLimit) (*[]model.OrderLimit, error){
+	childLogger.Info().Str("func","ListOrderLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.ListOrderLimit")
+	defer span.End()
+
+	return s.workerRepository.ListOrderLimit(ctx, orderLimit)
+}
+
+// About get an order limit
+func (s *WorkerService) GetOrderLimit(ctx context.Context, orderLimit model.OrderLimit) (*model.OrderLimit, error){
+	childLogger.Info().Str("func","GetOrderLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.GetOrderLimit")
+	defer span.End()
+
+	return s.workerRepository.GetOrderLimitById(ctx, orderLimit)
+}
+
+// About add an order limit
+func (s *WorkerService) AddOrderLimit(ctx context.Context, orderLimit model.OrderLimit) (*model.OrderLimit, error){
+	childLogger.Info().Str("func","AddOrderLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("orderLimit", orderLimit).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.AddOrderLimit")
+	defer span.End()
+
+	if err := s.validateOrderLimit(ctx, orderLimit); err != nil {
+		return nil, err
+	}
+
+	return s.workerRepository.AddOrderLimit(ctx, orderLimit)
+}
+
+// About update an order limit
+func (s *WorkerService) UpdateOrderLimit(ctx context.Context, orderLimit model.OrderLimit) (*model.OrderLimit, error){
+	childLogger.Info().Str("func","UpdateOrderLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("orderLimit", orderLimit).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.UpdateOrderLimit")
+	defer span.End()
+
+	if err := s.validateOrderLimit(ctx, orderLimit); err != nil {
+		return nil, err
+	}
+
+	res, err := s.workerRepository.UpdateOrderLimit(ctx, orderLimit)
+	if err != nil {
+		return nil, err
+	}
+	if res == 0 {
+		return nil, erro.ErrNotFound
+	}
+
+	return s.workerRepository.GetOrderLimitById(ctx, orderLimit)
+}
+
+// About delete an order limit
+func (s *WorkerService) DeleteOrderLimit(ctx context.Context, orderLimit model.OrderLimit) error {
+	childLogger.Info().Str("func","DeleteOrderLimit").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("orderLimit", orderLimit).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.DeleteOrderLimit")
+	defer span.End()
+
+	res, err := s.workerRepository.DeleteOrderLimit(ctx, orderLimit)
+	if err != nil {
+		return err
+	}
+	if res == 0 {
+		return erro.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package service
+
+import(
+	"time"
+	"context"
+	"strconv"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/erro"
+)
+
+// About the member of a consumption inside a window, the transaction_id when there is one
+func windowMember(limit model.Limit, now time.Time) string {
+	if limit.TransactionId != "" {
+		return limit.TransactionId
+	}
+
+	random := make([]byte, 8)
+	rand.Read(random)
+	return strconv.FormatInt(now.UnixNano(), 36) + "-" + hex.EncodeToString(random)
+}
+
+// About check the limit using the counter store
+// All windows are evaluated and consumed atomically by the counter store, the limit transactions
+// are written in the storage asynchronously (so they are returned without id)
+// The transaction_id and the decision are kept by transaction_id (as limit_request), at least for the longest window
+// A simulation is evaluated by the counter store as a check, but nothing is written
+func (s *WorkerService) checkLimitCounter(	ctx context.Context,
+											typeLimit model.TypeLimit,
+											limit model.Limit,
+											mode string,
+											payloadHash string,
+											requestKey string,
+											listOrderLimit []levelOrderLimit,
+											fxRates map[string]*model.FxRate,
+											simulate bool) (*model.LimitDecision, error){
+	childLogger.Info().Str("func","checkLimitCounter").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.checkLimitCounter")
+	defer span.End()
+
+	now := time.Now()
+	member := windowMember(limit, now)
+
+	limit_counter := model.LimitCounter{	TransactionId: limit.TransactionId,
+											Key: requestKey,
+											PayloadHash: payloadHash,
+											Simulate: simulate,
+										}
+
+	// prepare a window per order limit, an unknown counter consumes nothing and has no window
+	list_window := []limitWindow{}
+	list_consumption := []counterConsumption{}
+	list_window_index := []int{}
+	list_limit := []model.Limit{}
+	list_fx_rate := []*model.FxRate{}
+
+	for _, level_order_limit := range listOrderLimit {
+		val := level_order_limit.orderLimit
+		window, err := orderLimitWindow(typeLimit, val, now)
+		if err != nil {
+			return nil, err
+		}
+
+		// convert the amount to the currency of the order limit
+		level_limit := limit
+		level_limit.Key = level_order_limit.key
+		tmp_limit, tmp_fx_rate, err := s.convertLimit(ctx, level_limit, val, fxRates)
+		if err != nil {
+			return nil, err
+		}
+		consumption := counterConsumptionOf(tmp_limit, val, mode)
+
+		list_window = append(list_window, window)
+		list_consumption = append(list_consumption, consumption)
+		list_limit = append(list_limit, tmp_limit)
+		list_fx_rate = append(list_fx_rate, tmp_fx_rate)
+
+		if consumption.counter == "" {
+			list_window_index = append(list_window_index, -1)
+			continue
+		}
+
+		var tmp_expire_at *time.Time
+		if limit.ReservationTtl > 0 {
+			expire_at := now.Add(time.Duration(limit.ReservationTtl) * time.Second)
+			tmp_expire_at = &expire_at
+		}
+
+		list_window_index = append(list_window_index, len(limit_counter.Windows))
+		limit_counter.Windows = append(limit_counter.Windows, model.LimitWindow{	Key: level_order_limit.key,
+																					TypeLimit: val.TypeLimit,
+																					OrderLimit: val.Type,
+																					CounterLimit: val.CounterLimit,
+																					Member: member,
+																					WindowStart: window.start,
+																					WindowEnd: window.end,
+																					Mcc: limit.Mcc,
+																					Mccs: level_order_limit.scope.Mccs,
+																					RuleId: level_order_limit.scope.RuleId,
+																					Window: level_order_limit.scope.Window,
+																					Amount: consumption.amount,
+																					LimitAmount: val.Amount,
+																					ByQuantity: consumption.byQuantity,
+																					EvaluationMode: consumption.mode,
+																					ExpireAt: tmp_expire_at,
+																					CreateAt: now,
+																				})
+	}
+
+	// evaluate and consume
+	res_limit_counter, err := s.counterStore.ConsumeLimitCounter(ctx, limit_counter)
+	if err != nil {
+		return nil, err
+	}
+
+	// a replay returns the original decision
+	if res_limit_counter.Replay {
+		if res_limit_counter.ReplayHash != payloadHash {
+			return nil, erro.ErrConflict
+		}
+		res_limit_decision, err := s.counterStore.GetLimitDecision(ctx, limit.TransactionId)
+		if err == erro.ErrNotFound {
+			// the original is still being evaluated
+			return nil, erro.ErrConflict
+		}
+		if err != nil {
+			return nil, err
+		}
+		childLogger.Info().Str("func","checkLimitCounter").Str("transaction_id", limit.TransactionId).Msg("replay of a transaction_id already evaluated")
+		return res_limit_decision, nil
+	}
+
+	// Create a list of limit transaction
+	limit_decision := model.LimitDecision{	TransactionId: limit.TransactionId,
+											Decision: "APPROVED",
+											LimitTransactions: []model.LimitTransaction{},
+										}
+
+	list_evaluation := []orderLimitEvaluation{}
+	for i, level_order_limit := range listOrderLimit {
+		val := level_order_limit.orderLimit
+		usage := model.LimitUsage{}
+		if list_window_index[i] >= 0 {
+			usage = res_limit_counter.Windows[list_window_index[i]].Usage
+		}
+
+		limitTransaction, tmp_breach := evaluateOrderLimit(list_limit[i], val, list_consumption[i], usage, list_window[i], now)
+		s.applyFxRate(&limitTransaction, limit, val, list_fx_rate[i])
+		list_evaluation = append(list_evaluation, orderLimitEvaluation{	orderLimit: val,
+																		consumption: list_consumption[i],
+																		usage: usage,
+																		window: list_window[i],
+																		limitTransaction: limitTransaction,
+																		breach: tmp_breach,
+																	})
+	}
+
+	// the counter store consumed no window of a check breached, the order limits not violated are declined
+	declineEvaluation(list_evaluation, now)
+	for _, val := range list_evaluation {
+		addLimitDecision(&limit_decision, val.limitTransaction, val.orderLimit, val.breach)
+	}
+
+	// a simulation consumed nothing, so there is nothing to remember or to write
+	if simulate {
+		return &limit_decision, nil
+	}
+
+	// store the decision, so a replay of the transaction_id returns it (the counters are already consumed)
+	var limit_request *model.LimitRequest
+	if limit.TransactionId != "" {
+		err = s.counterStore.AddLimitDecision(ctx, limit_decision)
+		if err != nil {
+			childLogger.Error().Err(err).Str("transaction_id", limit.TransactionId).Msg("error store the decision")
+		}
+		limit_request = &model.LimitRequest{	TransactionId: limit.TransactionId,
+												PayloadHash: payloadHash,
+												Response: &limit_decision,
+											}
+	}
+
+	// write the ledger
+	s.enqueueLedger(ctx, ledgerEntry{	transactionId: limit.TransactionId,
+										limitTransactions: limit_decision.LimitTransactions,
+										limitRequest: limit_request,
+									})
+
+	return &limit_decision, nil
+}
+
+// About mirror the reversals in the counter store, the compensating entry keeps the created_at of the original
+func (s *WorkerService) reverseLimitCounter(ctx context.Context, listLimitTransaction []model.LimitTransaction){
+	for _, val := range listLimitTransaction {
+		err := s.counterStore.AddLimitWindow(ctx, val.Key, model.LimitWindow{	TypeLimit: val.TypeLimit,
+																				OrderLimit: val.OrderLimit,
+																				CounterLimit: val.CounterLimit,
+																				Member: val.TransactionId + "|R" + strconv.Itoa(val.ID),
+																				Amount: val.Amount,
+																				Mcc: val.Mcc,
+																				RuleId: val.RuleId,
+																				Window: val.Window,
+																				CreateAt: val.CreareAt,
+																			})
+		if err != nil {
+			childLogger.Error().Err(err).Str("transaction_id", val.TransactionId).Msg("error reverse the counter")
+		}
+	}
+}
+
+// About mirror the reservations settled in the counter store
+func (s *WorkerService) settleLimitCounter(ctx context.Context, listLimitTransaction []model.LimitTransaction, suffix string){
+	for _, val := range listLimitTransaction {
+		limit_window := model.LimitWindow{	TypeLimit: val.TypeLimit,
+											OrderLimit: val.OrderLimit,
+											CounterLimit: val.CounterLimit,
+											RuleId: val.RuleId,
+											Window: val.Window,
+											Member: val.TransactionId,
+										}
+
+		var err error
+		if suffix == ":RELEASED" {
+			err = s.counterStore.RemoveLimitWindow(ctx, val.Key, limit_window)
+		} else {
+			err = s.counterStore.ConfirmLimitWindow(ctx, val.Key, limit_window)
+		}
+		if err != nil {
+			childLogger.Error().Err(err).Str("transaction_id", val.TransactionId).Msg("error settle the counter")
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import(
+	"regexp"
+	"context"
+	"math/big"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/erro"
+)
+
+// About a currency is an ISO 4217 code (ex: BRL)
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// About validate a currency, empty means the currency of the limit (no conversion)
+func validateCurrency(currency string) error{
+	if currency != "" && !currencyCode.MatchString(currency) {
+		return erro.ErrBadRequest
+	}
+	return nil
+}
+
+// About the currency of a check or an order limit, empty is the base currency (empty when there is none)
+func (s *WorkerService) currencyOrBase(currency string) string{
+	if currency == "" {
+		return s.baseCurrency
+	}
+	return currency
+}
+
+// About convert the amount of a transaction to the currency of an order limit
+// Only a value counter is converted, the amounts of its window are always in a single currency: an empty currency
+// (of the check or of the order limit) is the base currency, without a base currency a check must have a currency
+// when the order limit has one, and a check with a currency has no currency to be converted to when the order
+// limit has none (both without currency are never converted)
+// The rates are cached by the caller for the whole check, so every order limit uses the same rate
+func (s *WorkerService) convertLimit(ctx context.Context,
+									limit model.Limit,
+									orderLimit model.OrderLimit,
+									fxRates map[string]*model.FxRate) (model.Limit, *model.FxRate, error){
+	if orderLimit.CounterLimit != "VALUE" {
+		return limit, nil, nil
+	}
+	order_currency := s.currencyOrBase(orderLimit.Currency)
+	if (order_currency == "") != (s.currencyOrBase(limit.Currency) == "") {
+		return limit, nil, erro.ErrBadRequest
+	}
+
+	return s.convertCurrency(ctx, limit, order_currency, fxRates)
+}
+
+// About convert the amount of a transaction to a currency, an amount already in the currency is not converted
+func (s *WorkerService) convertCurrency(ctx context.Context,
+										limit model.Limit,
+										currency string,
+										fxRates map[string]*model.FxRate) (model.Limit, *model.FxRate, error){
+	limit_currency := s.currencyOrBase(limit.Currency)
+	if currency == limit_currency {
+		return limit, nil, nil
+	}
+
+	fx_rate, ok := fxRates[currency]
+	if !ok {
+		if s.fxProvider == nil {
+			return limit, nil, erro.ErrFxRate
+		}
+
+		res_fx_rate, err := s.fxProvider.GetFxRate(ctx, model.FxRate{From: limit_currency, To: currency})
+		if err != nil {
+			return limit, nil, err
+		}
+		fx_rate = res_fx_rate
+		fxRates[currency] = fx_rate
+	}
+
+	rate, ok := new(big.Rat).SetString(fx_rate.Rate)
+	if !ok || rate.Sign() <= 0 {
+		return limit, nil, erro.ErrFxRate
+	}
+
+	// rounded half to even
+	limit.Amount = limit.Amount.MulRat(rate)
+	limit.Currency = currency
+
+	return limit, fx_rate, nil
+}
+
+// About record on the limit transaction the amount received and the rate used (audit)
+func (s *WorkerService) applyFxRate(limitTransaction *model.LimitTransaction, limit model.Limit, orderLimit model.OrderLimit, fxRate *model.FxRate){
+	if orderLimit.CounterLimit != "VALUE" {
+		return
+	}
+	limitTransaction.Currency = s.currencyOrBase(orderLimit.Currency)
+	if limitTransaction.Currency == "" {
+		limitTransaction.Currency = limit.Currency
+	}
+	if fxRate != nil {
+		limitTransaction.OriginalAmount = limit.Amount
+		limitTransaction.OriginalCurrency = s.currencyOrBase(limit.Currency)
+		limitTransaction.FxRate = fxRate.Rate
+	}
+}
+
+// About convert the amount of a reversal with the rate used by the original limit transaction
+func reversalAmount(amount model.Money, limitTransaction model.LimitTransaction) (model.Money, error){
+	if limitTransaction.FxRate == "" {
+		return amount, nil
+	}
+
+	rate, ok := new(big.Rat).SetString(limitTransaction.FxRate)
+	if !ok {
+		return 0, erro.ErrFxRate
+	}
+
+	return amount.MulRat(rate), nil
+}
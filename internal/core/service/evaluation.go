@@ -0,0 +1,188 @@
+package service
+
+import(
+	"time"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/erro"
+)
+
+// About the evaluation modes of a limit
+// POST_COMMIT compares only the amount already consumed inside the window (legacy behavior)
+// PRE_COMMIT compares the projected total, consumed plus the incoming transaction
+const (
+	evaluationPostCommit = "POST_COMMIT"
+	evaluationPreCommit  = "PRE_COMMIT"
+)
+
+// About validate the evaluation mode requested, empty means the legacy mode
+func evaluationMode(mode string) (string, error){
+	switch mode {
+	case "", evaluationPostCommit:
+		return evaluationPostCommit, nil
+	case evaluationPreCommit:
+		return evaluationPreCommit, nil
+	}
+	return "", erro.ErrBadRequest
+}
+
+// About check if a limit is breach and calculate the remaining headroom
+// The remaining is what is left after this transaction (when approved) and never goes below zero
+// The amounts are exact (minor units), so there is no rounding on the comparison
+func evaluateLimit(mode string, limitAmount, consumed, amount model.Money) (bool, model.Money){
+	var breach bool
+
+	if mode == evaluationPreCommit {
+		breach = consumed + amount > limitAmount
+	} else {
+		breach = consumed > limitAmount
+	}
+
+	remaining := limitAmount - consumed
+	if !breach {
+		remaining = remaining - amount
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return breach, remaining
+}
+
+// About what a transaction consumes from the counter of an order limit
+// An unknown counter consumes nothing (empty counter)
+type counterConsumption struct {
+	counter		string
+	amount		model.Money
+	byQuantity	bool
+	mode		string
+}
+
+// About the consumption of a counter limit, a rate counter counts one request and is always PRE_COMMIT
+func counterConsumptionOf(limit model.Limit, orderLimit model.OrderLimit, mode string) counterConsumption{
+	switch {
+	case orderLimit.CounterLimit == "VALUE":
+		return counterConsumption{counter: "VALUE", amount: limit.Amount, mode: mode}
+	case orderLimit.CounterLimit == "QUANTITY":
+		return counterConsumption{counter: "QUANTITY", amount: model.MoneyFromInt(int64(limit.Quantity)), mode: mode}
+	case isRateCounter(orderLimit.CounterLimit):
+		// each request counts one inside the rate window, so the incoming request is always included
+		return counterConsumption{counter: "RATE", amount: model.MoneyFromInt(1), byQuantity: true, mode: evaluationPreCommit}
+	}
+	return counterConsumption{mode: mode}
+}
+
+// About evaluate an order limit against the usage of its window and build the limit transaction
+func evaluateOrderLimit(limit model.Limit, 
+						orderLimit model.OrderLimit, 
+						consumption counterConsumption, 
+						usage model.LimitUsage, 
+						window limitWindow, 
+						now time.Time) (model.LimitTransaction, bool){
+	var tmp_consumed model.Money
+	var tmp_remaining model.Money
+	var tmp_status = "LIMIT:APROVED"
+	var tmp_breach bool
+
+	if consumption.counter != "" {
+		tmp_consumed = usage.Amount
+		if consumption.byQuantity {
+			tmp_consumed = model.MoneyFromInt(int64(usage.Quantity))
+		}
+		tmp_breach, tmp_remaining = evaluateLimit(consumption.mode, orderLimit.Amount, tmp_consumed, consumption.amount)
+		if tmp_breach {
+			tmp_status = "LIMIT:" + consumption.counter + ":BREACH"
+		} else if limit.ReservationTtl > 0 {
+			tmp_status = "LIMIT:" + consumption.counter + ":RESERVED"
+			tmp_consumed = tmp_consumed + consumption.amount
+		} else {
+			tmp_status = "LIMIT:" + consumption.counter + ":APPROVED"
+			tmp_consumed = tmp_consumed + consumption.amount
+		}
+	}
+
+	var tmp_expire_at *time.Time
+	if limit.ReservationTtl > 0 && !tmp_breach {
+		expire_at := now.Add(time.Duration(limit.ReservationTtl) * time.Second)
+		tmp_expire_at = &expire_at
+	}
+
+	limitTransaction := model.LimitTransaction{	TransactionId: limit.TransactionId,
+												Key: limit.Key,
+												TypeLimit: orderLimit.TypeLimit,
+												CounterLimit: orderLimit.CounterLimit,
+												OrderLimit: orderLimit.Type,
+												Status: tmp_status,
+												Amount: consumption.amount,
+												Mcc: limit.Mcc,
+												RuleId: orderLimitRuleId(orderLimit),
+												Window: windowScope(orderLimit),
+												AdjustmentId: orderLimit.AdjustmentId,
+												LimitAmount: orderLimit.Amount,
+												Consumed: tmp_consumed,
+												Remaining: tmp_remaining,
+												ResetAt: windowResetAt(usage.FirstCreateAt, window, now, !tmp_breach),
+												ExpireAt: tmp_expire_at,
+												CreareAt: now, 
+											}
+
+	return limitTransaction, tmp_breach
+}
+
+// About an order limit evaluated, kept until all order limits of the check are evaluated
+type orderLimitEvaluation struct {
+	orderLimit			model.OrderLimit
+	consumption			counterConsumption
+	usage				model.LimitUsage
+	window				limitWindow
+	limitTransaction	model.LimitTransaction
+	breach				bool
+}
+
+// About check if an order limit of the check is breached
+func isBreachEvaluation(listEvaluation []orderLimitEvaluation) bool {
+	for _, val := range listEvaluation {
+		if val.breach {
+			return true
+		}
+	}
+	return false
+}
+
+// About decline the order limits not violated of a check breached, so a check breached consumes nothing
+// The limit transaction is kept as DECLINED (never counted) with the headroom the order limit still has
+func declineEvaluation(listEvaluation []orderLimitEvaluation, now time.Time){
+	if !isBreachEvaluation(listEvaluation) {
+		return
+	}
+
+	for i, val := range listEvaluation {
+		if val.breach || val.consumption.counter == "" {
+			continue
+		}
+
+		tmp_consumed := val.limitTransaction.Consumed - val.consumption.amount
+		tmp_remaining := val.orderLimit.Amount - tmp_consumed
+		if tmp_remaining < 0 {
+			tmp_remaining = 0
+		}
+
+		limitTransaction := &listEvaluation[i].limitTransaction
+		limitTransaction.Status = "LIMIT:" + val.consumption.counter + ":DECLINED"
+		limitTransaction.Consumed = tmp_consumed
+		limitTransaction.Remaining = tmp_remaining
+		limitTransaction.ResetAt = windowResetAt(val.usage.FirstCreateAt, val.window, now, false)
+		limitTransaction.ExpireAt = nil
+	}
+}
+
+// About add a limit transaction to the decision, the first order limit violated decides the transaction
+func addLimitDecision(limitDecision *model.LimitDecision, limitTransaction model.LimitTransaction, orderLimit model.OrderLimit, breach bool){
+	limitDecision.LimitTransactions = append(limitDecision.LimitTransactions, limitTransaction)
+
+	if breach && limitDecision.BreachOrderLimit == nil {
+		breach_order_limit := orderLimit
+		limitDecision.Decision = "BREACH"
+		limitDecision.BreachOrderLimit = &breach_order_limit
+	}
+}
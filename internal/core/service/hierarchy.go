@@ -0,0 +1,126 @@
+package service
+
+import(
+	"sort"
+	"time"
+	"context"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/erro"
+	"github.com/go-limit/internal/core/port"
+)
+
+// About the levels of a check (ex: card, account and customer), a check without levels has a single one
+// Each level has its own key and its own order limits, a key appears only once
+func limitLevels(limit model.Limit) ([]model.LimitLevel, error){
+	if len(limit.Levels) == 0 {
+		return []model.LimitLevel{{OrderLimit: limit.OrderLimit, Key: limit.Key}}, nil
+	}
+
+	// the levels replace the key of the check
+	if limit.Key != "" || limit.OrderLimit != "" {
+		return nil, erro.ErrBadRequest
+	}
+
+	keys := map[string]bool{}
+	for _, val := range limit.Levels {
+		if val.Key == "" || val.OrderLimit == "" || keys[val.Key] {
+			return nil, erro.ErrBadRequest
+		}
+		keys[val.Key] = true
+	}
+
+	return limit.Levels, nil
+}
+
+// About the distinct keys of a list of limit transactions
+func limitTransactionKeys(listLimitTransaction []model.LimitTransaction) []string{
+	keys := []string{}
+	for _, val := range listLimitTransaction {
+		keys = append(keys, val.Key)
+	}
+	return keys
+}
+
+// About lock a list of keys, always in the same order so two checks sharing keys never deadlock
+func (s *WorkerService) lockKeys(ctx context.Context, tx port.Tx, keys []string) error{
+	sorted := append([]string{}, keys...)
+	sort.Strings(sorted)
+
+	for i, val := range sorted {
+		if i > 0 && val == sorted[i-1] {
+			continue
+		}
+		err := s.workerRepository.LockKey(ctx, tx, val)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// About an order limit of a level, evaluated against the key of the level
+// Only the consumption of the scope is counted by the order limit
+type levelOrderLimit struct {
+	key			string
+	orderLimit	model.OrderLimit
+	scope		model.LimitScope
+}
+
+// About get the order limits of all levels, in the order of the levels, merged with the overrides and the adjustments of each key
+// Only the rules matching the check apply (see ruleOrderLimit) and per counter only the order limit of the mcc of the check (see mccOrderLimit)
+// The rates used by the rules are cached on fxRates, the same rates of the evaluation of the check
+func (s *WorkerService) getLevelOrderLimit(	ctx context.Context,
+											typeLimit model.TypeLimit,
+											limit model.Limit,
+											levels []model.LimitLevel,
+											fxRates map[string]*model.FxRate) ([]levelOrderLimit, error){
+	list_level_order_limit := []levelOrderLimit{}
+	mcc_groups := map[string]*model.MccGroup{}
+	now := time.Now()
+
+	for _, level := range levels {
+		res_list_order_limit, err := s.workerRepository.GetOrderLimit(ctx, model.OrderLimit{	TypeLimit: limit.TypeLimit,
+																								CounterLimit: level.OrderLimit})
+		if err != nil {
+			return nil, err
+		}
+
+		// the rules matching the check and the most specific order limit for the mcc
+		env, err := expressionEnv(typeLimit, limit, level.Key, now)
+		if err != nil {
+			return nil, err
+		}
+		list_order_limit, err := s.ruleOrderLimit(ctx, env, limit, *res_list_order_limit, fxRates)
+		if err != nil {
+			return nil, err
+		}
+		list_order_limit, list_mccs, err := s.mccOrderLimit(ctx, limit.Mcc, list_order_limit, mcc_groups)
+		if err != nil {
+			return nil, err
+		}
+
+		// the overrides of the key replace the defaults of the type limit
+		list_order_limit, err = s.overrideOrderLimit(ctx, level.Key, list_order_limit)
+		if err != nil {
+			return nil, err
+		}
+
+		// the adjustments of the key in force are applied on top
+		list_order_limit, err = s.adjustOrderLimit(ctx, level.Key, list_order_limit)
+		if err != nil {
+			return nil, err
+		}
+		for i, val := range list_order_limit {
+			scope := model.LimitScope{Mccs: list_mccs[i], RuleId: orderLimitRuleId(val), Window: windowScope(val)}
+			if scope.RuleId > 0 {
+				// a rule only counts its own consumption, whatever the mcc
+				scope.Mccs = nil
+			}
+			list_level_order_limit = append(list_level_order_limit, levelOrderLimit{key: level.Key, orderLimit: val, scope: scope})
+		}
+	}
+
+	return list_level_order_limit, nil
+}
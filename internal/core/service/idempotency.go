@@ -0,0 +1,21 @@
+package service
+
+import(
+	"encoding/hex"
+	"encoding/json"
+	"crypto/sha256"
+
+	"github.com/go-limit/internal/core/model"
+)
+
+// About calculate the hash of the payload of a limit request
+// A replay of the same transaction_id must carry exactly the same payload
+func payloadHash(limit model.Limit) (string, error){
+	payload, err := json.Marshal(limit)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,148 @@
+package service
+
+import(
+	"time"
+	"context"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/erro"
+)
+
+// About the attempts to write an entry in the ledger, the wait doubles after each failure (0.1s up to 0.8s)
+const ledgerAttempts = 5
+const ledgerBackoff = 100 * time.Millisecond
+
+// About an entry of the ledger, the rows of a check are written in a single transaction
+type ledgerEntry struct {
+	transactionId		string
+	limitTransactions	[]model.LimitTransaction
+	limitRequest		*model.LimitRequest
+	done				chan struct{}
+}
+
+// About queue an entry to be written in the ledger
+// When the queue is full the entry is written synchronously, slowing down the checks (back pressure)
+func (s *WorkerService) enqueueLedger(ctx context.Context, entry ledgerEntry){
+	entry.done = make(chan struct{})
+
+	if entry.transactionId != "" {
+		s.ledgerMutex.Lock()
+		s.ledgerPending[entry.transactionId] = entry.done
+		s.ledgerMutex.Unlock()
+	}
+
+	select {
+	case s.ledger <- entry:
+	default:
+		childLogger.Warn().Str("func","enqueueLedger").Msg("ledger queue full, writing synchronously")
+		s.writeLedgerEntry(context.WithoutCancel(ctx), entry)
+	}
+}
+
+// About write an entry in the ledger, the counters were already consumed so a failure is retried with backoff
+// An entry still failing after all attempts is logged with its limit transactions
+func (s *WorkerService) writeLedgerEntry(ctx context.Context, entry ledgerEntry){
+	defer func() {
+		if entry.transactionId != "" {
+			s.ledgerMutex.Lock()
+			if s.ledgerPending[entry.transactionId] == entry.done {
+				delete(s.ledgerPending, entry.transactionId)
+			}
+			s.ledgerMutex.Unlock()
+		}
+		close(entry.done)
+	}()
+
+	backoff := ledgerBackoff
+	for attempt := 1; ; attempt++ {
+		err := s.addLedgerEntry(ctx, entry)
+		if err == nil {
+			return
+		}
+		if attempt == ledgerAttempts {
+			childLogger.Error().Err(err).Str("transaction_id", entry.transactionId).Interface("limitTransactions", entry.limitTransactions).Msg("error write ledger, entry lost")
+			return
+		}
+
+		childLogger.Warn().Err(err).Str("transaction_id", entry.transactionId).Int("attempt", attempt).Msg("error write ledger, retrying")
+		time.Sleep(backoff)
+		backoff = backoff * 2
+	}
+}
+
+// About write the rows of an entry in a single transaction
+func (s *WorkerService) addLedgerEntry(ctx context.Context, entry ledgerEntry) (err error){
+	// prepare batabase
+	tx, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	// handle connection
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			err = tx.Commit(ctx)
+		}
+	}()
+
+	for _, val := range entry.limitTransactions {
+		_, err = s.workerRepository.AddLimitTransaction(ctx, tx, val)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the counter store forgets a transaction_id before the storage does
+	if entry.limitRequest != nil {
+		_, err = s.workerRepository.GetLimitRequest(ctx, tx, *entry.limitRequest)
+		if err == erro.ErrNotFound {
+			_, err = s.workerRepository.AddLimitRequest(ctx, tx, *entry.limitRequest)
+		}
+	}
+	return err
+}
+
+// About write the ledger in background, the entries still queued are written when the context is done
+func (s *WorkerService) WriteLedger(ctx context.Context) {
+	childLogger.Info().Str("func","WriteLedger").Send()
+
+	if s.counterStore == nil {
+		return
+	}
+
+	for {
+		select {
+		case entry := <-s.ledger:
+			s.writeLedgerEntry(context.WithoutCancel(ctx), entry)
+		case <-ctx.Done():
+			for {
+				select {
+				case entry := <-s.ledger:
+					s.writeLedgerEntry(context.WithoutCancel(ctx), entry)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// About wait until the entry of a transaction_id (if queued) is written in the ledger
+func (s *WorkerService) flushLedger(ctx context.Context, transactionId string) error{
+	s.ledgerMutex.Lock()
+	done, ok := s.ledgerPending[transactionId]
+	s.ledgerMutex.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
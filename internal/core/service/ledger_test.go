@@ -0,0 +1,64 @@
+package service
+
+import(
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-limit/internal/adapter/memory"
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/port"
+)
+
+// a storage whose writes of limit transactions fail a number of times
+type failingRepository struct {
+	*memory.WorkerRepository
+	failures	int
+}
+
+func (r *failingRepository) AddLimitTransaction(ctx context.Context, tx port.Tx, limitTransaction model.LimitTransaction) (*model.LimitTransaction, error) {
+	// the second row of the entry fails, so the first one must be rolled back
+	if r.failures > 0 && limitTransaction.CounterLimit == "QUANTITY" {
+		r.failures--
+		return nil, errors.New("connection reset")
+	}
+	return r.WorkerRepository.AddLimitTransaction(ctx, tx, limitTransaction)
+}
+
+// an entry of the ledger that fails is written again, as a whole
+func TestWriteLedgerEntryRetry(t *testing.T) {
+	ctx := context.Background()
+	workerRepository := &failingRepository{WorkerRepository: memory.NewWorkerRepository(), failures: 2}
+	workerService := NewWorkerService(workerRepository, nil, nil, "", 0)
+
+	entry := ledgerEntry{	transactionId: "tx-1",
+							limitTransactions: []model.LimitTransaction{
+								{TransactionId: "tx-1", Key: "card-1", CounterLimit: "VALUE", Status: "LIMIT:VALUE:APPROVED", Amount: model.MoneyFromInt(10)},
+								{TransactionId: "tx-1", Key: "card-1", CounterLimit: "QUANTITY", Status: "LIMIT:QUANTITY:APPROVED", Amount: model.MoneyFromInt(1)},
+							},
+							limitRequest: &model.LimitRequest{TransactionId: "tx-1", PayloadHash: "hash-1"},
+							done: make(chan struct{}),
+						}
+	workerService.writeLedgerEntry(ctx, entry)
+
+	if workerRepository.failures != 0 {
+		t.Fatalf("failures left %d, want every failure retried", workerRepository.failures)
+	}
+
+	tx, err := workerRepository.StartTx(ctx)
+	if err != nil {
+		t.Fatalf("StartTx: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	res_list_limit_transaction, err := workerRepository.GetLimitTransactionByTransactionId(ctx, tx, model.LimitTransaction{TransactionId: "tx-1"})
+	if err != nil {
+		t.Fatalf("GetLimitTransactionByTransactionId: %v", err)
+	}
+	if len(*res_list_limit_transaction) != 2 {
+		t.Errorf("limit transactions %+v, want the 2 rows of the entry once", *res_list_limit_transaction)
+	}
+	if _, err := workerRepository.GetLimitRequest(ctx, tx, model.LimitRequest{TransactionId: "tx-1"}); err != nil {
+		t.Errorf("GetLimitRequest: %v, want the request written", err)
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import(
+	"context"
+	"strconv"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/erro"
+)
+
+// About how specific the mcc of an order limit is for the mcc of a check
+const (
+	mccNoMatch	= -1
+	mccWildcard	= 0
+	mccGroup	= 1
+	mccExact	= 2
+)
+
+// About check if a code is a merchant category code (4 digits)
+func isMcc(code string) bool {
+	if len(code) != 4 {
+		return false
+	}
+	for _, val := range code {
+		if val < '0' || val > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// About validate a mcc group, the code can not be taken for a mcc and the group has at least one mcc
+func validateMccGroup(mccGroup model.MccGroup) error {
+	if mccGroup.Code == "" || isMcc(mccGroup.Code) || len(mccGroup.Mccs) == 0 {
+		return erro.ErrBadRequest
+	}
+	for _, val := range mccGroup.Mccs {
+		if !isMcc(val) {
+			return erro.ErrBadRequest
+		}
+	}
+	return nil
+}
+
+// About validate the mcc of an order limit, empty (any mcc), a mcc or the code of a mcc group
+func (s *WorkerService) validateOrderLimitMcc(ctx context.Context, mcc string) error {
+	if mcc == "" || isMcc(mcc) {
+		return nil
+	}
+
+	_, err := s.workerRepository.GetMccGroup(ctx, model.MccGroup{Code: mcc})
+	if err == erro.ErrNotFound {
+		return erro.ErrBadRequest
+	}
+	return err
+}
+
+// About the mccs of an order limit (nil for any mcc) and how specific it is for the mcc of a check
+// The groups already read are kept in mccGroups, so a group is read once per check
+func (s *WorkerService) orderLimitMccs(ctx context.Context, mcc string, orderLimit model.OrderLimit, mccGroups map[string]*model.MccGroup) ([]string, int, error){
+	if orderLimit.Mcc == "" {
+		return nil, mccWildcard, nil
+	}
+	// a check without mcc only gets the order limits of any mcc
+	if mcc == "" {
+		return nil, mccNoMatch, nil
+	}
+	if isMcc(orderLimit.Mcc) {
+		if orderLimit.Mcc == mcc {
+			return []string{orderLimit.Mcc}, mccExact, nil
+		}
+		return nil, mccNoMatch, nil
+	}
+
+	res_mcc_group, ok := mccGroups[orderLimit.Mcc]
+	if !ok {
+		var err error
+		res_mcc_group, err = s.workerRepository.GetMccGroup(ctx, model.MccGroup{Code: orderLimit.Mcc})
+		if err == erro.ErrNotFound {
+			// the group was deleted, the order limit matches nothing
+			res_mcc_group, err = nil, nil
+		}
+		if err != nil {
+			return nil, mccNoMatch, err
+		}
+		mccGroups[orderLimit.Mcc] = res_mcc_group
+	}
+	if res_mcc_group == nil {
+		return nil, mccNoMatch, nil
+	}
+
+	for _, val := range res_mcc_group.Mccs {
+		if val == mcc {
+			return res_mcc_group.Mccs, mccGroup, nil
+		}
+	}
+	return nil, mccNoMatch, nil
+}
+
+// About the counter an order limit competes for with the order limits of other mccs
+// Each window of the counter is its own, and so is a rule
+func mccCounter(orderLimit model.OrderLimit) string {
+	counter := orderLimit.CounterLimit + ":" + windowScope(orderLimit)
+	if rule_id := orderLimitRuleId(orderLimit); rule_id > 0 {
+		return counter + ":" + strconv.Itoa(rule_id)
+	}
+	return counter
+}
+
+// About keep, per counter and window, only the most specific order limit for the mcc of a check
+// The same mcc comes first, then a group with the mcc and then the order limit of any mcc
+// A rule (an order limit with conditions or an expression) is never replaced by another order limit
+// It returns the order limits (in the same order) and the mccs counted by each one
+func (s *WorkerService) mccOrderLimit(ctx context.Context, mcc string, listOrderLimit []model.OrderLimit, mccGroups map[string]*model.MccGroup) ([]model.OrderLimit, [][]string, error){
+	list_mccs := make([][]string, len(listOrderLimit))
+	list_specific := make([]int, len(listOrderLimit))
+	best_specific := map[string]int{}
+
+	for i, val := range listOrderLimit {
+		tmp_mccs, tmp_specific, err := s.orderLimitMccs(ctx, mcc, val, mccGroups)
+		if err != nil {
+			return nil, nil, err
+		}
+		list_mccs[i] = tmp_mccs
+		list_specific[i] = tmp_specific
+
+		counter := mccCounter(val)
+		if best, ok := best_specific[counter]; !ok || tmp_specific > best {
+			best_specific[counter] = tmp_specific
+		}
+	}
+
+	list_order_limit := []model.OrderLimit{}
+	list_order_limit_mccs := [][]string{}
+	counters := map[string]bool{}
+	for i, val := range listOrderLimit {
+		counter := mccCounter(val)
+		if list_specific[i] == mccNoMatch || list_specific[i] != best_specific[counter] {
+			continue
+		}
+		// a mcc in two groups, the first group wins
+		if counters[counter] {
+			continue
+		}
+		counters[counter] = true
+		list_order_limit = append(list_order_limit, val)
+		list_order_limit_mccs = append(list_order_limit_mccs, list_mccs[i])
+	}
+
+	return list_order_limit, list_order_limit_mccs, nil
+}
+
+// About list all mcc groups
+func (s *WorkerService) ListMccGroup(ctx context.Context) (*[]model.MccGroup, error){
+	childLogger.Info().Str("func","ListMccGroup").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.ListMccGroup")
+	defer span.End()
+
+	return s.workerRepository.ListMccGroup(ctx)
+}
+
+// About get a mcc group
+func (s *WorkerService) GetMccGroup(ctx context.Context, mccGroup model.MccGroup) (*model.MccGroup, error){
+	childLogger.Info().Str("func","GetMccGroup").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.GetMccGroup")
+	defer span.End()
+
+	return s.workerRepository.GetMccGroup(ctx, mccGroup)
+}
+
+// About add a mcc group
+func (s *WorkerService) AddMccGroup(ctx context.Context, mccGroup model.MccGroup) (*model.MccGroup, error){
+	childLogger.Info().Str("func","AddMccGroup").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("mccGroup", mccGroup).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.AddMccGroup")
+	defer span.End()
+
+	if err := validateMccGroup(mccGroup); err != nil {
+		return nil, err
+	}
+
+	return s.workerRepository.AddMccGroup(ctx, mccGroup)
+}
+
+// About update the mccs of a mcc group
+func (s *WorkerService) UpdateMccGroup(ctx context.Context, mccGroup model.MccGroup) (*model.MccGroup, error){
+	childLogger.Info().Str("func","UpdateMccGroup").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("mccGroup", mccGroup).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.UpdateMccGroup")
+	defer span.End()
+
+	if err := validateMccGroup(mccGroup); err != nil {
+		return nil, err
+	}
+
+	res, err := s.workerRepository.UpdateMccGroup(ctx, mccGroup)
+	if err != nil {
+		return nil, err
+	}
+	if res == 0 {
+		return nil, erro.ErrNotFound
+	}
+
+	return s.workerRepository.GetMccGroup(ctx, mccGroup)
+}
+
+// About delete a mcc group, it is a conflict while there are order limits using it
+func (s *WorkerService) DeleteMccGroup(ctx context.Context, mccGroup model.MccGroup) error {
+	childLogger.Info().Str("func","DeleteMccGroup").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("mccGroup", mccGroup).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.DeleteMccGroup")
+	defer span.End()
+
+	res_list_order_limit, err := s.workerRepository.ListOrderLimit(ctx, model.OrderLimit{})
+	if err != nil {
+		return err
+	}
+	for _, val := range *res_list_order_limit {
+		if val.Mcc == mccGroup.Code {
+			return erro.ErrConflict
+		}
+	}
+
+	res, err := s.workerRepository.DeleteMccGroup(ctx, mccGroup)
+	if err != nil {
+		return err
+	}
+	if res == 0 {
+		return erro.ErrNotFound
+	}
+
+	return nil
+}
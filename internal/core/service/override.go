@@ -0,0 +1,133 @@
+package service
+
+import(
+	"time"
+	"context"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/erro"
+)
+
+// About validate an override, the order limit must exist and an expiry must be in the future
+// The amount may be zero (the key has no limit at all until the override expires)
+func (s *WorkerService) validateLimitOverride(ctx context.Context, limitOverride model.LimitOverride) error {
+	if limitOverride.Key == "" || limitOverride.OrderLimitId <= 0 || limitOverride.Amount < 0 {
+		return erro.ErrBadRequest
+	}
+
+	if limitOverride.ExpireAt != nil && !limitOverride.ExpireAt.After(time.Now()) {
+		return erro.ErrBadRequest
+	}
+
+	_, err := s.workerRepository.GetOrderLimitById(ctx, model.OrderLimit{ID: limitOverride.OrderLimitId})
+	if err == erro.ErrNotFound {
+		return erro.ErrBadRequest
+	}
+	return err
+}
+
+// About replace the amount of the order limits of a key by its overrides still active
+func (s *WorkerService) overrideOrderLimit(ctx context.Context, key string, listOrderLimit []model.OrderLimit) ([]model.OrderLimit, error){
+	res_list_limit_override, err := s.workerRepository.GetLimitOverride(ctx, model.LimitOverride{Key: key})
+	if err != nil {
+		return nil, err
+	}
+	if len(*res_list_limit_override) == 0 {
+		return listOrderLimit, nil
+	}
+
+	limit_override := map[int]model.LimitOverride{}
+	for _, val := range *res_list_limit_override {
+		limit_override[val.OrderLimitId] = val
+	}
+
+	list_order_limit := []model.OrderLimit{}
+	for _, val := range listOrderLimit {
+		if override, ok := limit_override[val.ID]; ok {
+			val.Amount = override.Amount
+			val.OverrideId = override.ID
+		}
+		list_order_limit = append(list_order_limit, val)
+	}
+
+	return list_order_limit, nil
+}
+
+// About list the overrides (optionally of a key)
+func (s *WorkerService) ListLimitOverride(ctx context.Context, limitOverride model.LimitOverride) (*[]model.LimitOverride, error){
+	childLogger.Info().Str("func","ListLimitOverride").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.ListLimitOverride")
+	defer span.End()
+
+	return s.workerRepository.ListLimitOverride(ctx, limitOverride)
+}
+
+// About get an override
+func (s *WorkerService) GetLimitOverride(ctx context.Context, limitOverride model.LimitOverride) (*model.LimitOverride, error){
+	childLogger.Info().Str("func","GetLimitOverride").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.GetLimitOverride")
+	defer span.End()
+
+	return s.workerRepository.GetLimitOverrideById(ctx, limitOverride)
+}
+
+// About add an override
+func (s *WorkerService) AddLimitOverride(ctx context.Context, limitOverride model.LimitOverride) (*model.LimitOverride, error){
+	childLogger.Info().Str("func","AddLimitOverride").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("limitOverride", limitOverride).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.AddLimitOverride")
+	defer span.End()
+
+	if err := s.validateLimitOverride(ctx, limitOverride); err != nil {
+		return nil, err
+	}
+
+	return s.workerRepository.AddLimitOverride(ctx, limitOverride)
+}
+
+// About update an override
+func (s *WorkerService) UpdateLimitOverride(ctx context.Context, limitOverride model.LimitOverride) (*model.LimitOverride, error){
+	childLogger.Info().Str("func","UpdateLimitOverride").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("limitOverride", limitOverride).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.UpdateLimitOverride")
+	defer span.End()
+
+	if err := s.validateLimitOverride(ctx, limitOverride); err != nil {
+		return nil, err
+	}
+
+	res, err := s.workerRepository.UpdateLimitOverride(ctx, limitOverride)
+	if err != nil {
+		return nil, err
+	}
+	if res == 0 {
+		return nil, erro.ErrNotFound
+	}
+
+	return s.workerRepository.GetLimitOverrideById(ctx, limitOverride)
+}
+
+// About delete an override
+func (s *WorkerService) DeleteLimitOverride(ctx context.Context, limitOverride model.LimitOverride) error {
+	childLogger.Info().Str("func","DeleteLimitOverride").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("limitOverride", limitOverride).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.DeleteLimitOverride")
+	defer span.End()
+
+	res, err := s.workerRepository.DeleteLimitOverride(ctx, limitOverride)
+	if err != nil {
+		return err
+	}
+	if res == 0 {
+		return erro.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package service
+
+import(
+	"time"
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/go-limit/internal/core/model"
+)
+
+// About the metrics of the retention
+type retentionMetric struct {
+	purged		metric.Int64Counter
+	runs		metric.Int64Counter
+	duration	metric.Float64Histogram
+}
+
+// About create the metrics of the retention, on the global meter provider (set up by the server with the traces)
+func newRetentionMetric() retentionMetric{
+	meter := otel.Meter("github.com/go-limit/internal/core/service")
+
+	purged, err := meter.Int64Counter("limit.retention.purged",
+										metric.WithDescription("rows purged by the retention"))
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error create metric")
+	}
+	runs, err := meter.Int64Counter("limit.retention.runs",
+										metric.WithDescription("runs of the retention"))
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error create metric")
+	}
+	duration, err := meter.Float64Histogram("limit.retention.duration",
+										metric.WithDescription("duration of a run of the retention"),
+										metric.WithUnit("s"))
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error create metric")
+	}
+
+	return retentionMetric{purged: purged, runs: runs, duration: duration}
+}
+
+// About calculate the cutoff of the retention, the longest window configured plus the grace period
+// Nothing older than the longest window is ever read by a check
+func (s *WorkerService) retentionCutoff(ctx context.Context, grace time.Duration, now time.Time) (time.Time, error){
+	res_list_order_limit, err := s.workerRepository.ListOrderLimit(ctx, model.OrderLimit{})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// a calendar window depends on the timezone of its type limit
+	res_list_type_limit, err := s.workerRepository.ListTypeLimit(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	type_limit := map[string]model.TypeLimit{}
+	for _, val := range *res_list_type_limit {
+		type_limit[val.Code] = val
+	}
+
+	var longest time.Duration
+	for _, val := range *res_list_order_limit {
+		window, err := orderLimitWindow(type_limit[val.TypeLimit], val, now)
+		if err != nil {
+			childLogger.Warn().Err(err).Int("order_limit_id", val.ID).Msg("order limit with invalid window ignored by the retention")
+			continue
+		}
+		if now.Sub(window.start) > longest {
+			longest = now.Sub(window.start)
+		}
+	}
+
+	return now.Add(-(longest + grace)), nil
+}
+
+// About purge periodically the limit transactions (with buckets and limit requests) out of every window
+func (s *WorkerService) RetainLimitTransaction(ctx context.Context, interval time.Duration, grace time.Duration, batchSize int, archive bool) {
+	childLogger.Info().Str("func","RetainLimitTransaction").Send()
+
+	if interval <= 0 || batchSize <= 0 {
+		childLogger.Info().Msg("retention disabled")
+		return
+	}
+
+	s.retentionMutex.Lock()
+	s.retentionStat.Enabled = true
+	s.retentionStat.Archive = archive
+	s.retentionMutex.Unlock()
+
+	retention_metric := newRetentionMetric()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.retainLimitTransaction(ctx, retention_metric, grace, batchSize, archive)
+		}
+	}
+}
+
+// About run the retention once, batch by batch until nothing is left (the progress is seen on the stat)
+func (s *WorkerService) retainLimitTransaction(ctx context.Context, retentionMetric retentionMetric, grace time.Duration, batchSize int, archive bool) {
+	start := time.Now()
+
+	s.retentionMutex.Lock()
+	s.retentionStat.Running = true
+	s.retentionStat.LastRunAt = &start
+	s.retentionStat.LastPurged = 0
+	s.retentionStat.LastError = ""
+	s.retentionMutex.Unlock()
+
+	// purge a table batch by batch
+	purge := func(table string, purgeBatch func() (int64, error)) error {
+		for ctx.Err() == nil {
+			res, err := purgeBatch()
+			if err != nil {
+				return err
+			}
+
+			retentionMetric.purged.Add(ctx, res, metric.WithAttributes(attribute.String("table", table)))
+			s.retentionMutex.Lock()
+			s.retentionStat.LastPurged = s.retentionStat.LastPurged + res
+			s.retentionStat.TotalPurged = s.retentionStat.TotalPurged + res
+			s.retentionMutex.Unlock()
+
+			if res < int64(batchSize) {
+				return nil
+			}
+		}
+		return ctx.Err()
+	}
+
+	cutoff, err := s.retentionCutoff(ctx, grace, start)
+	if err == nil {
+		s.retentionMutex.Lock()
+		s.retentionStat.LastCutoff = &cutoff
+		s.retentionMutex.Unlock()
+
+		err = purge("limit_transaction", func() (int64, error) {
+			return s.workerRepository.PurgeLimitTransaction(ctx, cutoff, batchSize, archive)
+		})
+	}
+	if err == nil {
+		err = purge("limit_counter_bucket", func() (int64, error) {
+			return s.workerRepository.PurgeCounterBucket(ctx, cutoff, batchSize)
+		})
+	}
+	if err == nil {
+		err = purge("limit_request", func() (int64, error) {
+			return s.workerRepository.PurgeLimitRequest(ctx, cutoff, batchSize)
+		})
+	}
+
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+		childLogger.Error().Err(err).Msg("error retention")
+	}
+	duration := time.Since(start)
+	retentionMetric.runs.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", outcome)))
+	retentionMetric.duration.Record(ctx, duration.Seconds())
+
+	s.retentionMutex.Lock()
+	s.retentionStat.Running = false
+	s.retentionStat.TotalRuns = s.retentionStat.TotalRuns + 1
+	s.retentionStat.LastDuration = float64(duration.Milliseconds())
+	if err != nil {
+		s.retentionStat.LastError = err.Error()
+	}
+	childLogger.Info().Interface("retention", s.retentionStat).Send()
+	s.retentionMutex.Unlock()
+}
@@ -0,0 +1,159 @@
+package service
+
+import(
+	"fmt"
+	"context"
+	"sync"
+	"time"
+	"strings"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/erro"
+	"github.com/go-limit/internal/core/expression"
+)
+
+// About the operators of a condition of an order limit
+const (
+	conditionEq		= "EQ"
+	conditionIn		= "IN"
+	conditionPrefix	= "PREFIX"
+)
+
+// About validate the attributes of a check, an attribute must have a name
+func validateAttributes(attributes map[string]string) error {
+	for key := range attributes {
+		if key == "" {
+			return erro.ErrBadRequest
+		}
+	}
+	return nil
+}
+
+// About validate the conditions of an order limit, EQ has a single value and IN or PREFIX at least one
+func validateConditions(conditions []model.LimitCondition) error {
+	for _, val := range conditions {
+		if val.Attribute == "" {
+			return erro.ErrBadRequest
+		}
+		switch val.Operator {
+		case conditionEq:
+			if len(val.Values) != 1 {
+				return erro.ErrBadRequest
+			}
+		case conditionIn, conditionPrefix:
+			if len(val.Values) == 0 {
+				return erro.ErrBadRequest
+			}
+		default:
+			return erro.ErrBadRequest
+		}
+	}
+	return nil
+}
+
+// About check if the attributes of a check match a condition, a missing attribute never matches
+func matchCondition(condition model.LimitCondition, attributes map[string]string) bool {
+	attribute, ok := attributes[condition.Attribute]
+	if !ok {
+		return false
+	}
+
+	for _, val := range condition.Values {
+		switch condition.Operator {
+		case conditionEq, conditionIn:
+			if attribute == val {
+				return true
+			}
+		case conditionPrefix:
+			if strings.HasPrefix(attribute, val) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// About the expressions already compiled, an expression is compiled once
+var expressionCache sync.Map
+
+// About compile the expression of an order limit, an invalid one is an ErrExpression with the position of the error
+func compileExpression(source string) (*expression.Program, error){
+	if program, ok := expressionCache.Load(source); ok {
+		return program.(*expression.Program), nil
+	}
+
+	program, err := expression.Compile(source)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", erro.ErrExpression, err)
+	}
+	expressionCache.Store(source, program)
+
+	return program, nil
+}
+
+// About what the expressions of the order limits of a level are evaluated against, the time is in the timezone of the type limit
+func expressionEnv(typeLimit model.TypeLimit, limit model.Limit, key string, now time.Time) (expression.Env, error){
+	location, err := typeLimitLocation(typeLimit)
+	if err != nil {
+		return expression.Env{}, err
+	}
+
+	return expression.Env{	Amount: limit.Amount,
+							Quantity: limit.Quantity,
+							Currency: limit.Currency,
+							Mcc: limit.Mcc,
+							Key: key,
+							Time: now.In(location),
+							Attributes: limit.Attributes,
+						}, nil
+}
+
+// About the rule of an order limit, the order limits with conditions or an expression are rules and count only their own consumption
+func orderLimitRuleId(orderLimit model.OrderLimit) int {
+	if len(orderLimit.Conditions) == 0 && orderLimit.Expression == "" {
+		return 0
+	}
+	return orderLimit.ID
+}
+
+// About keep the order limits that are not rules and the rules whose conditions (all of them) and expression match the check
+// The amount of an expression is in the currency of its order limit (converted with the rate of the check), unless
+// the order limit or the check has no currency
+func (s *WorkerService) ruleOrderLimit(	ctx context.Context,
+										env expression.Env,
+										limit model.Limit,
+										listOrderLimit []model.OrderLimit,
+										fxRates map[string]*model.FxRate) ([]model.OrderLimit, error){
+	list_order_limit := []model.OrderLimit{}
+
+	for _, val := range listOrderLimit {
+		match := true
+		for _, condition := range val.Conditions {
+			if !matchCondition(condition, env.Attributes) {
+				match = false
+				break
+			}
+		}
+		if match && val.Expression != "" {
+			program, err := compileExpression(val.Expression)
+			if err != nil {
+				return nil, err
+			}
+			rule_env := env
+			order_currency := s.currencyOrBase(val.Currency)
+			if order_currency != "" && s.currencyOrBase(limit.Currency) != "" {
+				tmp_limit, _, err := s.convertCurrency(ctx, limit, order_currency, fxRates)
+				if err != nil {
+					return nil, err
+				}
+				rule_env.Amount = tmp_limit.Amount
+			}
+			match = program.Eval(rule_env)
+		}
+		if match {
+			list_order_limit = append(list_order_limit, val)
+		}
+	}
+
+	return list_order_limit, nil
+}
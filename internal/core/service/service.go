@@ -1,15 +1,17 @@
 package service
 
 import(
+	"sync"
 	"time"
 	"context"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 
 	"github.com/go-limit/internal/core/model"
-	"github.com/go-limit/internal/adapter/database"
+	"github.com/go-limit/internal/core/erro"
+	"github.com/go-limit/internal/core/port"
 
-	go_core_pg "github.com/eliezerraj/go-core/database/pg"
 	go_core_observ "github.com/eliezerraj/go-core/observability"
 )
 
@@ -19,138 +21,550 @@ var (
 )
 
 type WorkerService struct {
-	workerRepository 	*database.WorkerRepository}
+	workerRepository 	port.WorkerRepository
+	counterStore		port.CounterStore
+	fxProvider			port.FxProvider
+	baseCurrency		string
+	ledger				chan ledgerEntry
+	ledgerMutex			sync.Mutex
+	ledgerPending		map[string]chan struct{}
+	retentionMutex		sync.Mutex
+	retentionStat		model.RetentionStat
+}
 
 // About create a new worker service
-func NewWorkerService(	workerRepository *database.WorkerRepository) *WorkerService{
+// The counter store is optional (nil), when used the storage is written as a ledger (queue of ledgerBuffer entries)
+// The fx provider is optional (nil), without it only the limits of the same currency are checked
+// The base currency is optional (empty), it is the currency of a check or an order limit without currency
+func NewWorkerService(	workerRepository port.WorkerRepository,
+						counterStore port.CounterStore,
+						fxProvider port.FxProvider,
+						baseCurrency string,
+						ledgerBuffer int) *WorkerService{
 	childLogger.Info().Str("func","NewWorkerService").Send()
 
 	return &WorkerService{
 		workerRepository: workerRepository,
+		counterStore: counterStore,
+		fxProvider: fxProvider,
+		baseCurrency: baseCurrency,
+		ledger: make(chan ledgerEntry, ledgerBuffer),
+		ledgerPending: map[string]chan struct{}{},
 	}
 }
 
-// About handle/convert http status code
-func (s *WorkerService) Stat(ctx context.Context) (go_core_pg.PoolStats){
+// About the stats of the pool and the progress of the retention
+func (s *WorkerService) Stat(ctx context.Context) (model.LimitStat){
 	childLogger.Info().Str("func","Stat").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
 
-	return s.workerRepository.Stat(ctx)
+	s.retentionMutex.Lock()
+	retention_stat := s.retentionStat
+	s.retentionMutex.Unlock()
+
+	return model.LimitStat{	PoolStats: s.workerRepository.Stat(ctx),
+							Retention: &retention_stat,
+						}
 }
 
 // About check the limit
-func (s *WorkerService) CheckLimitTransaction(ctx context.Context, limit model.Limit) (*[]model.LimitTransaction, error){
+func (s *WorkerService) CheckLimitTransaction(ctx context.Context, limit model.Limit) (*model.LimitDecision, error){
 	childLogger.Info().Str("func","CheckLimitTransaction").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("limit", limit).Send()
 
 	// trace
 	span := tracerProvider.Span(ctx, "service.CheckLimitTransaction")
 	defer span.End()
-	
+
+	return s.checkLimitTransaction(ctx, limit, false)
+}
+
+// About simulate a check of the limit (ex: would this purchase be approved?), the evaluation is the same of a check
+// and the response has the same shape, but nothing is consumed: the transaction is always rolled back
+func (s *WorkerService) SimulateLimitTransaction(ctx context.Context, limit model.Limit) (*model.LimitDecision, error){
+	childLogger.Info().Str("func","SimulateLimitTransaction").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("limit", limit).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.SimulateLimitTransaction")
+	defer span.End()
+
+	return s.checkLimitTransaction(ctx, limit, true)
+}
+
+// About evaluate a check of the limit, a simulation writes inside a transaction that is never committed
+func (s *WorkerService) checkLimitTransaction(ctx context.Context, limit model.Limit, simulate bool) (*model.LimitDecision, error){
+	// check the evaluation mode
+	mode, err := evaluationMode(limit.EvaluationMode)
+	if err != nil {
+		return nil, err
+	}
+
+	// a reservation holds the limit until it is confirmed, released or expired
+	if limit.ReservationTtl < 0 {
+		return nil, erro.ErrBadRequest
+	}
+
+	// a negative consumption would give back headroom, only a reversal releases the limit
+	if limit.Amount < 0 || limit.Quantity < 0 {
+		return nil, erro.ErrBadRequest
+	}
+
+	err = validateCurrency(limit.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	// the mcc of the merchant is optional, without it only the order limits of any mcc apply
+	if limit.Mcc != "" && !isMcc(limit.Mcc) {
+		return nil, erro.ErrBadRequest
+	}
+
+	// the attributes of the check (ex: channel, country), matched by the conditions of the rules
+	err = validateAttributes(limit.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	// the levels of the check (ex: card, account and customer), every level must have headroom
+	levels, err := limitLevels(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// hash the payload as received, used to detect a replay
+	payload_hash, err := payloadHash(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// check the type limit
+	type_limit := model.TypeLimit{Code: limit.TypeLimit}
+	res_type_limit, err := s.workerRepository.GetTypeLimit(ctx, type_limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// get list order limit of every level, the rates are fetched once for the whole check
+	fx_rates := map[string]*model.FxRate{}
+	res_lis_order_limit, err := s.getLevelOrderLimit(ctx, *res_type_limit, limit, levels, fx_rates)
+	if err != nil {
+		return nil, err
+	}
+
+	//childLogger.Info().Interface("== 1 ===> res_lis_order_limit", res_lis_order_limit ).Send()
+
+	// the counters are kept by the counter store, the storage is written later as a ledger
+	if s.counterStore != nil {
+		return s.checkLimitCounter(ctx, *res_type_limit, limit, mode, payload_hash, levels[0].Key, res_lis_order_limit, fx_rates, simulate)
+	}
+
 	// prepare batabase
-	tx, conn, err := s.workerRepository.DatabasePGServer.StartTx(ctx)
+	tx, err := s.workerRepository.StartTx(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer s.workerRepository.DatabasePGServer.ReleaseTx(conn)
 	
-	// handle connection
+	// handle connection, a simulation is always rolled back
 	defer func() {
-		if err != nil {
+		if err != nil || simulate {
 			tx.Rollback(ctx)
 		} else {
 			tx.Commit(ctx)
 		}
-		span.End()
 	}()
 
-	// check the type limit
-	type_limit := model.TypeLimit{Code: limit.TypeLimit}
-	_, err = s.workerRepository.GetTypeLimit(ctx, type_limit)
-	if err != nil {
-		return nil, err
+	// serialize the consumption of the keys of all levels, the locks are released at commit/rollback
+	list_key := []string{}
+	for _, val := range levels {
+		list_key = append(list_key, val.Key)
 	}
-
-	// get list order limit
-	res_order_limit := model.OrderLimit{TypeLimit: limit.TypeLimit,
-										CounterLimit: limit.OrderLimit}
-
-	res_lis_order_limit, err := s.workerRepository.GetOrderLimit(ctx, res_order_limit)
+	err = s.lockKeys(ctx, tx, list_key)
 	if err != nil {
 		return nil, err
 	}
 
-	//childLogger.Info().Interface("== 1 ===> res_lis_order_limit", res_lis_order_limit ).Send()
+	// check if the transaction_id was already evaluated, a replay returns the original decision
+	if limit.TransactionId != "" {
+		res_limit_request, err_request := s.workerRepository.GetLimitRequest(ctx, tx, model.LimitRequest{TransactionId: limit.TransactionId})
+		if err_request != nil && err_request != erro.ErrNotFound {
+			err = err_request
+			return nil, err
+		}
+		if res_limit_request != nil {
+			if res_limit_request.PayloadHash != payload_hash {
+				err = erro.ErrConflict
+				return nil, err
+			}
+			childLogger.Info().Str("func","CheckLimitTransaction").Str("transaction_id", limit.TransactionId).Msg("replay of a transaction_id already evaluated")
+			return res_limit_request.Response, nil
+		}
+	}
 
 	// Create a list of limit transaction
-	list_limitTransaction := []model.LimitTransaction{}
+	limit_decision := model.LimitDecision{	TransactionId: limit.TransactionId,
+											Decision: "APPROVED",
+											LimitTransactions: []model.LimitTransaction{},
+										}
+	now := time.Now()
+	list_evaluation := []orderLimitEvaluation{}
 
-	// for each order limit check the limit transaction 
-	for _, val := range *res_lis_order_limit{
+	// for each order limit (of every level) evaluate the limit transaction, nothing is written before all are evaluated
+	for _, level_order_limit := range res_lis_order_limit{
 
+		val := level_order_limit.orderLimit
+		limit.Key = level_order_limit.key
 		limit.TypeLimit = val.TypeLimit
 		limit.OrderLimit = val.Type
 		limit.CounterLimit = val.CounterLimit
+
+		// get the window of the order limit (in the timezone of the type limit)
+		var window limitWindow
+		window, err = orderLimitWindow(*res_type_limit, val, now)
+		if err != nil {
+			return nil, err
+		}
 		
-		// get all transaction per key and per count limit
-		res_limit_trans_per_key, err := s.workerRepository.GetLimitTransactionPerKey(ctx, limit)
+		// get all transaction per key and per count limit inside the window (same tx, after the lock)
+		var res_limit_usage *model.LimitUsage
+		res_limit_usage, err = s.workerRepository.GetLimitTransactionPerKey(ctx, tx, limit, level_order_limit.scope, window.start)
 		if err != nil {
 				return nil, err
 		}
 
-		//childLogger.Info().Interface("== 22 ===> res_limit_trans_per_key", res_limit_trans_per_key ).Send()
+		// convert the amount to the currency of the order limit
+		var tmp_limit model.Limit
+		var tmp_fx_rate *model.FxRate
+		tmp_limit, tmp_fx_rate, err = s.convertLimit(ctx, limit, val, fx_rates)
+		if err != nil {
+			return nil, err
+		}
 
 		// check if the limit is breach
-		var tmp_amount float64
-		var tmp_status = "LIMIT:APROVED"
-
-		if val.CounterLimit == "VALUE" {
-			//childLogger.Info().Interface("== 22 VALUE ===> float64(res_limit_trans_per_key.Amount) ", float64(res_limit_trans_per_key.Amount) ).Send()
-			//childLogger.Info().Interface("== 22 VALUE ===> val.Amount ", val.Amount ).Send()
-
-			tmp_amount =  limit.Amount 
-			if float64(res_limit_trans_per_key.Amount) > float64(val.Amount) {
-				tmp_status = "LIMIT:VALUE:BREACH"
-			} else {
-				tmp_status  = "LIMIT:VALUE:APPROVED"
-			}
+		tmp_consumption := counterConsumptionOf(tmp_limit, val, mode)
+		limitTransaction, tmp_breach := evaluateOrderLimit(	tmp_limit, 
+															val, 
+															tmp_consumption, 
+															*res_limit_usage, 
+															window, 
+															now)
+		s.applyFxRate(&limitTransaction, limit, val, tmp_fx_rate)
+
+		list_evaluation = append(list_evaluation, orderLimitEvaluation{	orderLimit: val,
+																		consumption: tmp_consumption,
+																		usage: *res_limit_usage,
+																		window: window,
+																		limitTransaction: limitTransaction,
+																		breach: tmp_breach,
+																	})
+	}
+
+	// a check breached consumes nothing, the order limits not violated are declined
+	declineEvaluation(list_evaluation, now)
+
+	for _, val := range list_evaluation {
+		// save the transaction
+		var res_limit_transaction *model.LimitTransaction
+		res_limit_transaction, err = s.workerRepository.AddLimitTransaction(ctx, tx, val.limitTransaction)
+		if err != nil {
+			return nil, err
 		}
 
-		if val.CounterLimit == "QUANTITY" {
-			//childLogger.Info().Interface("== 22 QUANTITY ===> res_limit_trans_per_key", float64(res_limit_trans_per_key.Amount) ).Send()
-			//childLogger.Info().Interface("== 22 QUANTITY ===> val.Amount", float64(val.Amount) ).Send()
+		// the row of a simulation is rolled back, so it has no id (as in the counter store)
+		if !simulate {
+			val.limitTransaction.ID = res_limit_transaction.ID
+		}
+		addLimitDecision(&limit_decision, val.limitTransaction, val.orderLimit, val.breach)
+	}
 
-			tmp_amount =  float64(limit.Quantity)
-			if float64(res_limit_trans_per_key.Amount) > float64(val.Amount) {
-				tmp_status = "LIMIT:QUANTITY:BREACH"
-			} else {
-				tmp_status  = "LIMIT:QUANTITY:APPROVED"
-			}
+	// store the decision, so a replay of the transaction_id does not consume again
+	if limit.TransactionId != "" && !simulate {
+		_, err = s.workerRepository.AddLimitRequest(ctx, tx, model.LimitRequest{	TransactionId: limit.TransactionId,
+																					PayloadHash: payload_hash,
+																					Response: &limit_decision,
+																				})
+		if err != nil {
+			return nil, err
 		}
+	}
+
+	return &limit_decision, nil
+}
+
+// About reverse (full or partial) a limit transaction, releasing the limit consumed
+func (s *WorkerService) ReverseLimitTransaction(ctx context.Context, limitReversal model.LimitReversal) (*[]model.LimitTransaction, error){
+	childLogger.Info().Str("func","ReverseLimitTransaction").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("limitReversal", limitReversal).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.ReverseLimitTransaction")
+	defer span.End()
+
+	if limitReversal.TransactionId == "" || limitReversal.Amount < 0 || limitReversal.Quantity < 0 {
+		return nil, erro.ErrBadRequest
+	}
+
+	if s.counterStore == nil {
+		return s.reverseLimitTransaction(ctx, limitReversal)
+	}
+
+	// the consumption must be in the ledger before being reversed
+	err := s.flushLedger(ctx, limitReversal.TransactionId)
+	if err != nil {
+		return nil, err
+	}
 
-		if val.CounterLimit == "MINUTE" {
+	res_list_limit_transaction, err := s.reverseLimitTransaction(ctx, limitReversal)
+	if err != nil {
+		return nil, err
+	}
+	s.reverseLimitCounter(ctx, *res_list_limit_transaction)
+
+	return res_list_limit_transaction, nil
+}
+
+// About reverse the limit transactions in the storage
+func (s *WorkerService) reverseLimitTransaction(ctx context.Context, limitReversal model.LimitReversal) (*[]model.LimitTransaction, error){
+	// prepare batabase
+	tx, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	
+	// handle connection
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			tx.Commit(ctx)
+		}
+	}()
+
+	// get the original limit transactions (locked)
+	res_list_limit_transaction, err := s.workerRepository.GetLimitTransactionByTransactionId(ctx, tx, model.LimitTransaction{TransactionId: limitReversal.TransactionId})
+	if err != nil {
+		return nil, err
+	}
+
+	// serialize with the consumption of the keys (all levels of the transaction)
+	err = s.lockKeys(ctx, tx, limitTransactionKeys(*res_list_limit_transaction))
+	if err != nil {
+		return nil, err
+	}
+
+	// without amount and quantity everything still consumed is reversed
+	full_reversal := limitReversal.Amount == 0 && limitReversal.Quantity == 0
+	now := time.Now()
+
+	list_limitTransaction := []model.LimitTransaction{}
+
+	for _, val := range *res_list_limit_transaction {
+		// only an approved consumption could be reversed, a rate counter is never released
+		if !strings.HasSuffix(val.Status, ":APPROVED") {
+			continue
+		}
+
+		available := val.Amount - val.ReversedAmount
+		if available <= 0 {
+			continue
+		}
+
+		var tmp_reverse model.Money
+		switch val.CounterLimit {
+		case "VALUE":
+			// the amount is in the currency of the transaction, converted with the rate of the original
+			tmp_reverse, err = reversalAmount(limitReversal.Amount, val)
+			if err != nil {
+				return nil, err
+			}
+		case "QUANTITY":
+			tmp_reverse = model.MoneyFromInt(int64(limitReversal.Quantity))
+		default:
 			continue
 		}
+		if full_reversal {
+			tmp_reverse = available
+		}
+		if tmp_reverse == 0 {
+			continue
+		}
+		if tmp_reverse > available {
+			err = erro.ErrBadRequest
+			return nil, err
+		}
+
+		// mark the original
+		val.ReversedAmount = val.ReversedAmount + tmp_reverse
+		val.ReversedAt = &now
+		if val.ReversedAmount >= val.Amount {
+			val.Status = "LIMIT:" + val.CounterLimit + ":REVERSED"
+		}
+
+		_, err = s.workerRepository.UpdateLimitTransactionReversal(ctx, tx, val)
+		if err != nil {
+			return nil, err
+		}
 
-		limitTransaction := model.LimitTransaction{	TransactionId: limit.TransactionId,
-													Key: limit.Key,
-													TypeLimit: limit.TypeLimit,
+		// the compensating entry keeps the created_at of the original, so both leave the window together
+		limitTransaction := model.LimitTransaction{	TransactionId: val.TransactionId,
+													Key: val.Key,
+													TypeLimit: val.TypeLimit,
 													CounterLimit: val.CounterLimit,
-													OrderLimit: limit.OrderLimit,
-													Status: tmp_status,
-													Amount: tmp_amount,
-													CreareAt: time.Now(), 
-												} 
-			
-		// save the transaction
-		res_limit_transaction, err := s.workerRepository.AddLimitTransaction(ctx, tx, limitTransaction)
+													OrderLimit: val.OrderLimit,
+													Status: "LIMIT:" + val.CounterLimit + ":REVERSAL",
+													Amount: -tmp_reverse,
+													Currency: val.Currency,
+													Mcc: val.Mcc,
+													RuleId: val.RuleId,
+													Window: val.Window,
+													ReferenceId: val.ID,
+													CreareAt: val.CreareAt,
+												}
+
+		var res_limit_transaction *model.LimitTransaction
+		res_limit_transaction, err = s.workerRepository.AddLimitTransaction(ctx, tx, limitTransaction)
+		if err != nil {
+			return nil, err
+		}
+
+		list_limitTransaction = append(list_limitTransaction, *res_limit_transaction)
+	}
+
+	// nothing left to reverse
+	if len(list_limitTransaction) == 0 {
+		err = erro.ErrNotFound
+		return nil, err
+	}
+
+	return &list_limitTransaction, nil
+}
+
+// About confirm a reservation, the limit held becomes consumed
+func (s *WorkerService) ConfirmLimitTransaction(ctx context.Context, limitTransaction model.LimitTransaction) (*[]model.LimitTransaction, error){
+	childLogger.Info().Str("func","ConfirmLimitTransaction").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("limitTransaction", limitTransaction).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.ConfirmLimitTransaction")
+	defer span.End()
+
+	return s.settleLimitReservation(ctx, limitTransaction, ":APPROVED")
+}
+
+// About release a reservation, the limit held is given back
+func (s *WorkerService) ReleaseLimitTransaction(ctx context.Context, limitTransaction model.LimitTransaction) (*[]model.LimitTransaction, error){
+	childLogger.Info().Str("func","ReleaseLimitTransaction").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("limitTransaction", limitTransaction).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "service.ReleaseLimitTransaction")
+	defer span.End()
+
+	return s.settleLimitReservation(ctx, limitTransaction, ":RELEASED")
+}
+
+// About move all reservations alive of a transaction_id to the final status
+func (s *WorkerService) settleLimitReservation(ctx context.Context, limitTransaction model.LimitTransaction, suffix string) (*[]model.LimitTransaction, error){
+	if limitTransaction.TransactionId == "" {
+		return nil, erro.ErrBadRequest
+	}
+
+	if s.counterStore == nil {
+		return s.settleLimitTransaction(ctx, limitTransaction, suffix)
+	}
+
+	// the reservation must be in the ledger before being settled
+	err := s.flushLedger(ctx, limitTransaction.TransactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	res_list_limit_transaction, err := s.settleLimitTransaction(ctx, limitTransaction, suffix)
+	if err != nil {
+		return nil, err
+	}
+	s.settleLimitCounter(ctx, *res_list_limit_transaction, suffix)
+
+	return res_list_limit_transaction, nil
+}
+
+// About settle the reservations in the storage
+func (s *WorkerService) settleLimitTransaction(ctx context.Context, limitTransaction model.LimitTransaction, suffix string) (*[]model.LimitTransaction, error){
+	// prepare batabase
+	tx, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	
+	// handle connection
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			tx.Commit(ctx)
+		}
+	}()
+
+	// get the reservations (locked)
+	res_list_limit_transaction, err := s.workerRepository.GetLimitTransactionByTransactionId(ctx, tx, limitTransaction)
+	if err != nil {
+		return nil, err
+	}
+
+	// serialize with the consumption of the keys (all levels of the transaction)
+	err = s.lockKeys(ctx, tx, limitTransactionKeys(*res_list_limit_transaction))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	list_limitTransaction := []model.LimitTransaction{}
+
+	for _, val := range *res_list_limit_transaction {
+		// an expired reservation (even not swept yet) can not be settled
+		if !strings.HasSuffix(val.Status, ":RESERVED") || val.ExpireAt == nil || val.ExpireAt.Before(now) {
+			continue
+		}
+
+		val.Status = strings.TrimSuffix(val.Status, ":RESERVED") + suffix
+		val.ExpireAt = nil
+
+		_, err = s.workerRepository.UpdateLimitTransactionStatus(ctx, tx, val)
 		if err != nil {
 			return nil, err
 		}
 
-		//childLogger.Info().Interface("---3333--->res_limit_transaction",res_limit_transaction ).Send()
+		list_limitTransaction = append(list_limitTransaction, val)
+	}
 
-		limitTransaction.ID = res_limit_transaction.ID
-		list_limitTransaction = append(list_limitTransaction, limitTransaction)
+	// nothing reserved (or already expired)
+	if len(list_limitTransaction) == 0 {
+		err = erro.ErrNotFound
+		return nil, err
 	}
 
 	return &list_limitTransaction, nil
 }
+
+// About sweep periodically the reservations expired
+func (s *WorkerService) SweepLimitReservation(ctx context.Context, interval time.Duration) {
+	childLogger.Info().Str("func","SweepLimitReservation").Send()
+
+	if interval <= 0 {
+		childLogger.Info().Msg("reservation sweeper disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			res, err := s.workerRepository.ExpireLimitReservation(ctx)
+			if err != nil {
+				childLogger.Error().Err(err).Msg("error sweep reservations")
+				continue
+			}
+			if res > 0 {
+				childLogger.Info().Int64("expired", res).Msg("reservations expired")
+			}
+		}
+	}
+}
@@ -0,0 +1,407 @@
+package service
+
+import(
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-limit/internal/adapter/fx"
+	"github.com/go-limit/internal/adapter/memory"
+	"github.com/go-limit/internal/core/erro"
+	"github.com/go-limit/internal/core/model"
+)
+
+// a worker service over the in-memory storage, with the type limit CREDIT and its order limits
+func newTestService(t *testing.T, listOrderLimit ...model.OrderLimit) (*WorkerService, *memory.WorkerRepository) {
+	t.Helper()
+
+	ctx := context.Background()
+	workerRepository := memory.NewWorkerRepository()
+	workerService := NewWorkerService(workerRepository, nil, nil, "", 0)
+
+	_, err := workerService.AddTypeLimit(ctx, model.TypeLimit{Code: "CREDIT", Category: "CARD"})
+	if err != nil {
+		t.Fatalf("AddTypeLimit: %v", err)
+	}
+	for _, val := range listOrderLimit {
+		val.TypeLimit = "CREDIT"
+		if val.Type == "" {
+			val.Type = "CREDIT"
+		}
+		_, err := workerService.AddOrderLimit(ctx, val)
+		if err != nil {
+			t.Fatalf("AddOrderLimit %+v: %v", val, err)
+		}
+	}
+
+	return workerService, workerRepository
+}
+
+// a check of the key card-1 with the projected total (PRE_COMMIT)
+func testLimit(transactionId string, amount int64) model.Limit {
+	return model.Limit{	TransactionId: transactionId,
+						Key: "card-1",
+						TypeLimit: "CREDIT",
+						OrderLimit: "CREDIT",
+						Amount: model.MoneyFromInt(amount),
+						EvaluationMode: evaluationPreCommit,
+					}
+}
+
+func mustCheck(t *testing.T, workerService *WorkerService, limit model.Limit, decision string) *model.LimitDecision {
+	t.Helper()
+
+	res, err := workerService.CheckLimitTransaction(context.Background(), limit)
+	if err != nil {
+		t.Fatalf("CheckLimitTransaction %s: %v", limit.TransactionId, err)
+	}
+	if res.Decision != decision {
+		t.Fatalf("CheckLimitTransaction %s: decision = %s, want %s (%+v)", limit.TransactionId, res.Decision, decision, res.LimitTransactions)
+	}
+	return res
+}
+
+// the limit transaction of the window of a decision
+func windowTransaction(t *testing.T, limitDecision *model.LimitDecision, window string) model.LimitTransaction {
+	t.Helper()
+
+	for _, val := range limitDecision.LimitTransactions {
+		if val.Window == window {
+			return val
+		}
+	}
+	t.Fatalf("no limit transaction of the window %s in %+v", window, limitDecision.LimitTransactions)
+	return model.LimitTransaction{}
+}
+
+// a consumption of the key created at a time in the past (ex: out of the window), committed directly in the storage
+func addConsumption(t *testing.T, workerRepository *memory.WorkerRepository, window string, amount int64, createAt time.Time) {
+	t.Helper()
+
+	ctx := context.Background()
+	tx, err := workerRepository.StartTx(ctx)
+	if err != nil {
+		t.Fatalf("StartTx: %v", err)
+	}
+	defer tx.Commit(ctx)
+
+	_, err = workerRepository.AddLimitTransaction(ctx, tx, model.LimitTransaction{	TransactionId: "tx-old",
+																					Key: "card-1",
+																					TypeLimit: "CREDIT",
+																					CounterLimit: "VALUE",
+																					OrderLimit: "CREDIT",
+																					Status: "LIMIT:VALUE:APPROVED",
+																					Amount: model.MoneyFromInt(amount),
+																					Window: window,
+																					CreareAt: createAt,
+																				})
+	if err != nil {
+		t.Fatalf("AddLimitTransaction: %v", err)
+	}
+}
+
+// only the consumption inside the window of an order limit is counted
+func TestCheckLimitWindow(t *testing.T) {
+	workerService, workerRepository := newTestService(t,
+		model.OrderLimit{CounterLimit: "VALUE", Amount: model.MoneyFromInt(100), Window: "DAY"},
+		model.OrderLimit{CounterLimit: "VALUE", Amount: model.MoneyFromInt(100), Window: "HOUR"},
+	)
+	now := time.Now()
+
+	// out of the DAY, inside the DAY but out of the HOUR and a consumption of the HOUR counted by the HOUR only
+	addConsumption(t, workerRepository, "DAY", 70, now.Add(-25 * time.Hour))
+	addConsumption(t, workerRepository, "DAY", 30, now.Add(-2 * time.Hour))
+	addConsumption(t, workerRepository, "HOUR", 20, now.Add(-10 * time.Minute))
+
+	res := mustCheck(t, workerService, testLimit("tx-1", 10), "APPROVED")
+	if day := windowTransaction(t, res, "DAY"); day.Consumed != model.MoneyFromInt(40) || day.Remaining != model.MoneyFromInt(60) {
+		t.Errorf("DAY consumed %v remaining %v, want 40 and 60", day.Consumed, day.Remaining)
+	}
+	hour := windowTransaction(t, res, "HOUR")
+	if hour.Consumed != model.MoneyFromInt(30) || hour.Remaining != model.MoneyFromInt(70) {
+		t.Errorf("HOUR consumed %v remaining %v, want 30 and 70", hour.Consumed, hour.Remaining)
+	}
+	// the oldest consumption of the HOUR leaves it in 50 minutes
+	if hour.ResetAt == nil || hour.ResetAt.Sub(now.Add(50 * time.Minute)).Abs() > time.Second {
+		t.Errorf("HOUR reset_at %v, want %v", hour.ResetAt, now.Add(50 * time.Minute))
+	}
+
+	mustCheck(t, workerService, testLimit("tx-2", 61), "BREACH")
+}
+
+// a check breached by one window consumes nothing of the other windows
+func TestCheckLimitBreachConsumesNothing(t *testing.T) {
+	workerService, _ := newTestService(t,
+		model.OrderLimit{CounterLimit: "VALUE", Amount: model.MoneyFromInt(100), Window: "DAY"},
+		model.OrderLimit{CounterLimit: "VALUE", Amount: model.MoneyFromInt(1000), Window: "MONTH"},
+	)
+
+	mustCheck(t, workerService, testLimit("tx-1", 60), "APPROVED")
+
+	res := mustCheck(t, workerService, testLimit("tx-2", 50), "BREACH")
+	if res.BreachOrderLimit == nil || res.BreachOrderLimit.Window != "DAY" {
+		t.Errorf("breach order limit = %+v, want the DAY", res.BreachOrderLimit)
+	}
+	month := windowTransaction(t, res, "MONTH")
+	if !strings.HasSuffix(month.Status, ":DECLINED") {
+		t.Errorf("status of the MONTH = %s, want DECLINED", month.Status)
+	}
+	if month.Consumed != model.MoneyFromInt(60) || month.Remaining != model.MoneyFromInt(940) {
+		t.Errorf("MONTH consumed %v remaining %v, want 60 and 940", month.Consumed, month.Remaining)
+	}
+
+	// the headroom of the DAY is still 40 and the MONTH only counted the first check
+	res = mustCheck(t, workerService, testLimit("tx-3", 40), "APPROVED")
+	if month := windowTransaction(t, res, "MONTH"); month.Consumed != model.MoneyFromInt(100) {
+		t.Errorf("MONTH consumed %v, want 100", month.Consumed)
+	}
+}
+
+// a replay of a transaction_id returns the decision already taken and consumes nothing, another payload is a conflict
+func TestCheckLimitReplay(t *testing.T) {
+	workerService, _ := newTestService(t,
+		model.OrderLimit{CounterLimit: "VALUE", Amount: model.MoneyFromInt(100), Window: "DAY"},
+	)
+
+	first := mustCheck(t, workerService, testLimit("tx-1", 60), "APPROVED")
+	replay := mustCheck(t, workerService, testLimit("tx-1", 60), "APPROVED")
+	if replay.LimitTransactions[0].ID != first.LimitTransactions[0].ID {
+		t.Errorf("replay limit transaction %d, want %d", replay.LimitTransactions[0].ID, first.LimitTransactions[0].ID)
+	}
+
+	_, err := workerService.CheckLimitTransaction(context.Background(), testLimit("tx-1", 10))
+	if err != erro.ErrConflict {
+		t.Errorf("replay with another payload: %v, want conflict", err)
+	}
+
+	// the replay did not consume again
+	mustCheck(t, workerService, testLimit("tx-2", 40), "APPROVED")
+}
+
+// a reservation holds the limit until it is released or confirmed
+func TestCheckLimitReservation(t *testing.T) {
+	ctx := context.Background()
+	workerService, _ := newTestService(t,
+		model.OrderLimit{CounterLimit: "VALUE", Amount: model.MoneyFromInt(100), Window: "DAY"},
+	)
+
+	reserve := func(transactionId string, amount int64, decision string) *model.LimitDecision {
+		limit := testLimit(transactionId, amount)
+		limit.ReservationTtl = 60
+		return mustCheck(t, workerService, limit, decision)
+	}
+
+	res := reserve("tx-1", 80, "APPROVED")
+	if status := res.LimitTransactions[0].Status; status != "LIMIT:VALUE:RESERVED" || res.LimitTransactions[0].ExpireAt == nil {
+		t.Fatalf("status %s expires_at %v, want a reservation", status, res.LimitTransactions[0].ExpireAt)
+	}
+	reserve("tx-2", 30, "BREACH")
+
+	// the headroom held is given back
+	_, err := workerService.ReleaseLimitTransaction(ctx, model.LimitTransaction{TransactionId: "tx-1"})
+	if err != nil {
+		t.Fatalf("ReleaseLimitTransaction: %v", err)
+	}
+	_, err = workerService.ReleaseLimitTransaction(ctx, model.LimitTransaction{TransactionId: "tx-1"})
+	if err != erro.ErrNotFound {
+		t.Errorf("release of a reservation already released: %v, want not found", err)
+	}
+
+	reserve("tx-3", 70, "APPROVED")
+	res_list_limit_transaction, err := workerService.ConfirmLimitTransaction(ctx, model.LimitTransaction{TransactionId: "tx-3"})
+	if err != nil {
+		t.Fatalf("ConfirmLimitTransaction: %v", err)
+	}
+	if status := (*res_list_limit_transaction)[0].Status; status != "LIMIT:VALUE:APPROVED" {
+		t.Errorf("status %s, want LIMIT:VALUE:APPROVED", status)
+	}
+
+	// the confirmed reservation is consumed
+	mustCheck(t, workerService, testLimit("tx-4", 31), "BREACH")
+	mustCheck(t, workerService, testLimit("tx-5", 30), "APPROVED")
+}
+
+// a reversal gives back (all or part of) the limit consumed
+func TestReverseLimitTransaction(t *testing.T) {
+	ctx := context.Background()
+	workerService, _ := newTestService(t,
+		model.OrderLimit{CounterLimit: "VALUE", Amount: model.MoneyFromInt(100), Window: "DAY"},
+	)
+
+	mustCheck(t, workerService, testLimit("tx-1", 80), "APPROVED")
+	mustCheck(t, workerService, testLimit("tx-2", 30), "BREACH")
+
+	_, err := workerService.ReverseLimitTransaction(ctx, model.LimitReversal{TransactionId: "tx-1", Amount: model.MoneyFromInt(50)})
+	if err != nil {
+		t.Fatalf("ReverseLimitTransaction: %v", err)
+	}
+	res := mustCheck(t, workerService, testLimit("tx-3", 70), "APPROVED")
+	if consumed := res.LimitTransactions[0].Consumed; consumed != model.MoneyFromInt(100) {
+		t.Errorf("consumed %v, want 100", consumed)
+	}
+
+	// a transaction_id never checked has nothing to reverse
+	_, err = workerService.ReverseLimitTransaction(ctx, model.LimitReversal{TransactionId: "tx-9"})
+	if err != erro.ErrNotFound {
+		t.Errorf("reversal of an unknown transaction: %v, want not found", err)
+	}
+}
+
+// a simulation is evaluated as a check but nothing is consumed or remembered
+func TestSimulateLimitTransaction(t *testing.T) {
+	ctx := context.Background()
+	workerService, _ := newTestService(t,
+		model.OrderLimit{CounterLimit: "VALUE", Amount: model.MoneyFromInt(100), Window: "DAY"},
+		model.OrderLimit{CounterLimit: "QUANTITY", Amount: model.MoneyFromInt(1), Window: "DAY"},
+	)
+
+	for i := 0; i < 2; i++ {
+		limit := testLimit("tx-1", 100)
+		limit.Quantity = 1
+		res, err := workerService.SimulateLimitTransaction(ctx, limit)
+		if err != nil {
+			t.Fatalf("SimulateLimitTransaction: %v", err)
+		}
+		if res.Decision != "APPROVED" || len(res.LimitTransactions) != 2 {
+			t.Fatalf("simulation %d: %+v, want approved on both counters", i, res)
+		}
+		for _, val := range res.LimitTransactions {
+			if val.ID != 0 {
+				t.Errorf("simulation %d: limit transaction with id %d", i, val.ID)
+			}
+		}
+	}
+
+	// the same transaction_id is evaluated (and consumed) by a real check
+	limit := testLimit("tx-1", 100)
+	limit.Quantity = 1
+	mustCheck(t, workerService, limit, "APPROVED")
+
+	res, err := workerService.SimulateLimitTransaction(ctx, testLimit("tx-2", 1))
+	if err != nil {
+		t.Fatalf("SimulateLimitTransaction: %v", err)
+	}
+	if res.Decision != "BREACH" {
+		t.Errorf("simulation after the check: %s, want BREACH", res.Decision)
+	}
+}
+
+// an order limit of zero blocks every check (ex: a blocked card), a negative one is rejected
+func TestOrderLimitZero(t *testing.T) {
+	workerService, _ := newTestService(t,
+		model.OrderLimit{CounterLimit: "VALUE", Amount: model.MoneyFromInt(0), Window: "DAY"},
+	)
+
+	mustCheck(t, workerService, testLimit("tx-1", 1), "BREACH")
+
+	_, err := workerService.AddOrderLimit(context.Background(), model.OrderLimit{	TypeLimit: "CREDIT",
+																				CounterLimit: "VALUE",
+																				Type: "CREDIT",
+																				Amount: model.MoneyFromInt(-1),
+																				Window: "HOUR",
+																			})
+	if err != erro.ErrBadRequest {
+		t.Errorf("order limit with a negative amount: %v, want bad request", err)
+	}
+}
+
+// the retention purges the limit requests with the limit transactions, a purged transaction_id is evaluated again
+func TestRetainLimitRequest(t *testing.T) {
+	ctx := context.Background()
+	workerService, workerRepository := newTestService(t,
+		model.OrderLimit{CounterLimit: "VALUE", Amount: model.MoneyFromInt(100), Window: "DAY"},
+	)
+
+	mustCheck(t, workerService, testLimit("tx-1", 60), "APPROVED")
+
+	// a negative grace moves the cutoff after the check
+	workerService.retainLimitTransaction(ctx, newRetentionMetric(), -48*time.Hour, 10, false)
+	if workerService.retentionStat.LastError != "" {
+		t.Fatalf("retention: %s", workerService.retentionStat.LastError)
+	}
+
+	tx, err := workerRepository.StartTx(ctx)
+	if err != nil {
+		t.Fatalf("StartTx: %v", err)
+	}
+	_, err = workerRepository.GetLimitRequest(ctx, tx, model.LimitRequest{TransactionId: "tx-1"})
+	tx.Rollback(ctx)
+	if err != erro.ErrNotFound {
+		t.Fatalf("GetLimitRequest after the retention: %v, want not found", err)
+	}
+
+	// nothing is left of the window, so the same transaction_id with another payload is a new check
+	mustCheck(t, workerService, testLimit("tx-1", 100), "APPROVED")
+}
+
+// an order limit without currency is in the base currency, a check in another currency is converted to it
+func TestCheckLimitBaseCurrency(t *testing.T) {
+	ctx := context.Background()
+	fxProvider, err := fx.NewStaticFxProvider("USD/BRL=5")
+	if err != nil {
+		t.Fatalf("NewStaticFxProvider: %v", err)
+	}
+	workerService := NewWorkerService(memory.NewWorkerRepository(), nil, fxProvider, "BRL", 0)
+
+	_, err = workerService.AddTypeLimit(ctx, model.TypeLimit{Code: "CREDIT", Category: "CARD"})
+	if err != nil {
+		t.Fatalf("AddTypeLimit: %v", err)
+	}
+	_, err = workerService.AddOrderLimit(ctx, model.OrderLimit{	TypeLimit: "CREDIT",
+																CounterLimit: "VALUE",
+																Type: "CREDIT",
+																Amount: model.MoneyFromInt(100),
+																Window: "DAY",
+															})
+	if err != nil {
+		t.Fatalf("AddOrderLimit: %v", err)
+	}
+
+	// a check without currency is in the base currency too
+	mustCheck(t, workerService, testLimit("tx-1", 50), "APPROVED")
+
+	limit := testLimit("tx-2", 8)
+	limit.Currency = "USD"
+	res := mustCheck(t, workerService, limit, "APPROVED")
+	limitTransaction := windowTransaction(t, res, "DAY")
+	if limitTransaction.Currency != "BRL" || limitTransaction.Amount != model.MoneyFromInt(40) {
+		t.Errorf("limit transaction %s %s, want 40.00 BRL", limitTransaction.Amount, limitTransaction.Currency)
+	}
+	if limitTransaction.OriginalCurrency != "USD" || limitTransaction.OriginalAmount != model.MoneyFromInt(8) || limitTransaction.FxRate == "" {
+		t.Errorf("limit transaction original %s %s rate %s, want 8.00 USD with its rate", limitTransaction.OriginalAmount, limitTransaction.OriginalCurrency, limitTransaction.FxRate)
+	}
+
+	limit = testLimit("tx-3", 11)
+	limit.Currency = "BRL"
+	mustCheck(t, workerService, limit, "BREACH")
+}
+
+// the amount of an expression is compared in the currency of its order limit
+func TestCheckLimitExpressionCurrency(t *testing.T) {
+	fxProvider, err := fx.NewStaticFxProvider("USD/BRL=5")
+	if err != nil {
+		t.Fatalf("NewStaticFxProvider: %v", err)
+	}
+	workerService, _ := newTestService(t,
+		model.OrderLimit{CounterLimit: "QUANTITY", Amount: model.MoneyFromInt(10), Window: "DAY"},
+		model.OrderLimit{CounterLimit: "VALUE", Amount: model.MoneyFromInt(1000), Currency: "BRL", Window: "DAY", Expression: "amount > 100"},
+	)
+	workerService.fxProvider = fxProvider
+
+	// 30 USD is 150 BRL, the rule applies
+	limit := testLimit("tx-1", 30)
+	limit.Currency = "USD"
+	res := mustCheck(t, workerService, limit, "APPROVED")
+	if len(res.LimitTransactions) != 2 {
+		t.Errorf("limit transactions %+v, want the rule applied", res.LimitTransactions)
+	}
+
+	// 10 USD is 50 BRL, only the order limit that is not a rule
+	limit = testLimit("tx-2", 10)
+	limit.Currency = "USD"
+	res = mustCheck(t, workerService, limit, "APPROVED")
+	if len(res.LimitTransactions) != 1 {
+		t.Errorf("limit transactions %+v, want the rule not applied", res.LimitTransactions)
+	}
+}
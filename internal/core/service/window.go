@@ -0,0 +1,192 @@
+package service
+
+import(
+	"sync"
+	"time"
+	"strings"
+
+	"github.com/go-limit/internal/core/model"
+	"github.com/go-limit/internal/core/erro"
+)
+
+// About the window used when the order limit has no window configured
+const defaultWindow = "MINUTE"
+
+// About the calendar windows, aligned on the timezone of the type limit
+// A CALENDAR_DAY resets at the local midnight, a CALENDAR_MONTH at the local midnight of the billing cycle day
+const (
+	windowCalendarDay   = "CALENDAR_DAY"
+	windowCalendarMonth = "CALENDAR_MONTH"
+)
+
+// About the bounds of the window of an order limit, only a calendar window has a fixed end (the next reset)
+type limitWindow struct {
+	start	time.Time
+	end		*time.Time
+}
+
+// About the timezones already loaded (the zoneinfo is read from the disk on each load)
+var timezoneCache sync.Map
+
+// About load the timezone of a type limit, empty means UTC
+func typeLimitLocation(typeLimit model.TypeLimit) (*time.Location, error){
+	if typeLimit.Timezone == "" {
+		return time.UTC, nil
+	}
+	if location, ok := timezoneCache.Load(typeLimit.Timezone); ok {
+		return location.(*time.Location), nil
+	}
+
+	location, err := time.LoadLocation(typeLimit.Timezone)
+	if err != nil {
+		return nil, erro.ErrBadRequest
+	}
+	timezoneCache.Store(typeLimit.Timezone, location)
+
+	return location, nil
+}
+
+// About the first instant of a day in a timezone, the day may be out of the month (ex: 32 is the next month)
+// When a DST change skips the midnight (ex: America/Sao_Paulo on 2018-11-04) time.Date normalizes it
+// to the day before (23:00), so the day starts when the change happens (01:00)
+func localMidnight(year int, month time.Month, day int, location *time.Location) time.Time{
+	// the noon is never skipped, so it gives the day once normalized
+	noon := time.Date(year, month, day, 12, 0, 0, 0, location)
+	midnight := time.Date(noon.Year(), noon.Month(), noon.Day(), 0, 0, 0, 0, location)
+	if midnight.Day() != noon.Day() {
+		_, midnight = midnight.ZoneBounds()
+	}
+	return midnight
+}
+
+// About the local midnight of a day of a month, a day after the end of the month is its last day (ex: 31 in april)
+func cycleBoundary(year int, month time.Month, day int, location *time.Location) time.Time{
+	last_day := time.Date(year, month + 1, 0, 12, 0, 0, 0, location).Day()
+	if day > last_day {
+		day = last_day
+	}
+	return localMidnight(year, month, day, location)
+}
+
+// About calculate the bounds of a calendar window in the timezone of the type limit
+func calendarWindow(window string, typeLimit model.TypeLimit, now time.Time) (limitWindow, error){
+	location, err := typeLimitLocation(typeLimit)
+	if err != nil {
+		return limitWindow{}, err
+	}
+	local := now.In(location)
+
+	var start, end time.Time
+	switch window {
+	case windowCalendarDay:
+		start = localMidnight(local.Year(), local.Month(), local.Day(), location)
+		end = localMidnight(local.Year(), local.Month(), local.Day() + 1, location)
+	case windowCalendarMonth:
+		cycle_day := typeLimit.BillingCycleDay
+		if cycle_day <= 0 {
+			cycle_day = 1
+		}
+		start = cycleBoundary(local.Year(), local.Month(), cycle_day, location)
+		if local.Before(start) {
+			end = start
+			start = cycleBoundary(local.Year(), local.Month() - 1, cycle_day, location)
+		} else {
+			end = cycleBoundary(local.Year(), local.Month() + 1, cycle_day, location)
+		}
+	}
+
+	return limitWindow{start: start, end: &end}, nil
+}
+
+// About the rate counters, each one counts the requests per key inside its own rolling window
+var rateCounterWindow = map[string]time.Duration{
+	"SECOND":	time.Second,
+	"MINUTE":	time.Minute,
+	"HOUR":		time.Hour,
+}
+
+// About check if the counter limit is a rate counter
+func isRateCounter(counterLimit string) bool {
+	_, ok := rateCounterWindow[counterLimit]
+	return ok
+}
+
+// About calculate the start of the rolling window of an order limit
+// The window could be MINUTE, HOUR, DAY, MONTH or an arbitrary duration (ex: 15m, 36h)
+func windowStart(window string, now time.Time) (time.Time, error){
+	switch strings.ToUpper(strings.TrimSpace(window)) {
+	case "", defaultWindow:
+		return now.Add(-time.Minute), nil
+	case "HOUR":
+		return now.Add(-time.Hour), nil
+	case "DAY":
+		return now.AddDate(0, 0, -1), nil
+	case "MONTH":
+		return now.AddDate(0, -1, 0), nil
+	}
+
+	duration, err := time.ParseDuration(strings.TrimSpace(window))
+	if err != nil || duration <= 0 {
+		return time.Time{}, erro.ErrInvalidWindow
+	}
+
+	return now.Add(-duration), nil
+}
+
+// About the window an order limit counts its consumption in, the order limits of a counter with another window
+// never count it (ex: a DAY and a MONTH of the same counter). A rate counter is its own window
+func windowScope(orderLimit model.OrderLimit) string {
+	if isRateCounter(orderLimit.CounterLimit) {
+		return orderLimit.CounterLimit
+	}
+
+	window := strings.TrimSpace(orderLimit.Window)
+	switch strings.ToUpper(window) {
+	case "":
+		return defaultWindow
+	case defaultWindow, "HOUR", "DAY", "MONTH", windowCalendarDay, windowCalendarMonth:
+		return strings.ToUpper(window)
+	}
+	return window
+}
+
+// About calculate the window of an order limit
+// A rate counter always uses its own window, otherwise the order limit window is used (rolling or calendar)
+func orderLimitWindow(typeLimit model.TypeLimit, orderLimit model.OrderLimit, now time.Time) (limitWindow, error){
+	if duration, ok := rateCounterWindow[orderLimit.CounterLimit]; ok {
+		return limitWindow{start: now.Add(-duration)}, nil
+	}
+
+	window := strings.ToUpper(strings.TrimSpace(orderLimit.Window))
+	if window == windowCalendarDay || window == windowCalendarMonth {
+		return calendarWindow(window, typeLimit, now)
+	}
+
+	start, err := windowStart(orderLimit.Window, now)
+	if err != nil {
+		return limitWindow{}, err
+	}
+	return limitWindow{start: start}, nil
+}
+
+// About calculate when the window resets
+// A calendar window resets at its end, a rolling window when its oldest consumption leaves the window
+// When nothing was consumed yet, the transaction itself (if consumed) opens the rolling window
+func windowResetAt(firstCreateAt *time.Time, window limitWindow, now time.Time, consumed bool) *time.Time{
+	if window.end != nil {
+		reset_at := *window.end
+		return &reset_at
+	}
+
+	length := now.Sub(window.start)
+
+	if firstCreateAt != nil {
+		reset_at := firstCreateAt.Add(length)
+		return &reset_at
+	}
+	if consumed {
+		reset_at := now.Add(length)
+		return &reset_at
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import(
+	"testing"
+	"time"
+
+	_ "time/tzdata"
+
+	"github.com/go-limit/internal/core/model"
+)
+
+// the midnight of the first day of the DST is skipped in these timezones, the day starts at 01:00
+func TestCalendarWindowDST(t *testing.T) {
+	tests := []struct {
+		name		string
+		timezone	string
+		window		string
+		cycleDay	int
+		now			string
+		start		string
+		end			string
+	}{
+		{"sao paulo day of the change", "America/Sao_Paulo", windowCalendarDay, 0, "2018-11-04T12:00:00-02:00", "2018-11-04T01:00:00-02:00", "2018-11-05T00:00:00-02:00"},
+		{"sao paulo day before the change", "America/Sao_Paulo", windowCalendarDay, 0, "2018-11-03T12:00:00-03:00", "2018-11-03T00:00:00-03:00", "2018-11-04T01:00:00-02:00"},
+		{"sao paulo end of the DST", "America/Sao_Paulo", windowCalendarDay, 0, "2019-02-16T12:00:00-02:00", "2019-02-16T00:00:00-02:00", "2019-02-17T00:00:00-03:00"},
+		{"sao paulo month starting on the change", "America/Sao_Paulo", windowCalendarMonth, 4, "2018-11-20T12:00:00-02:00", "2018-11-04T01:00:00-02:00", "2018-12-04T00:00:00-02:00"},
+		{"sao paulo month ending on the change", "America/Sao_Paulo", windowCalendarMonth, 4, "2018-10-20T12:00:00-03:00", "2018-10-04T00:00:00-03:00", "2018-11-04T01:00:00-02:00"},
+		{"havana day of the change", "America/Havana", windowCalendarDay, 0, "2023-03-12T12:00:00-04:00", "2023-03-12T01:00:00-04:00", "2023-03-13T00:00:00-04:00"},
+		{"havana day before the change", "America/Havana", windowCalendarDay, 0, "2023-03-11T12:00:00-05:00", "2023-03-11T00:00:00-05:00", "2023-03-12T01:00:00-04:00"},
+		{"beirut day of the change", "Asia/Beirut", windowCalendarDay, 0, "2022-03-27T12:00:00+03:00", "2022-03-27T01:00:00+03:00", "2022-03-28T00:00:00+03:00"},
+		{"beirut day before the change", "Asia/Beirut", windowCalendarDay, 0, "2022-03-26T12:00:00+02:00", "2022-03-26T00:00:00+02:00", "2022-03-27T01:00:00+03:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := mustParseTime(t, tt.now)
+			typeLimit := model.TypeLimit{Timezone: tt.timezone, BillingCycleDay: tt.cycleDay}
+
+			window, err := calendarWindow(tt.window, typeLimit, now)
+			if err != nil {
+				t.Fatalf("calendarWindow: %v", err)
+			}
+			if !window.start.Equal(mustParseTime(t, tt.start)) {
+				t.Errorf("start = %v, want %v", window.start, tt.start)
+			}
+			if !window.end.Equal(mustParseTime(t, tt.end)) {
+				t.Errorf("end = %v, want %v", window.end, tt.end)
+			}
+			// the window always contains now, so the reset is never in the past
+			if now.Before(window.start) || !now.Before(*window.end) {
+				t.Errorf("now %v outside of the window [%v, %v)", now, window.start, window.end)
+			}
+		})
+	}
+}
+
+func mustParseTime(t *testing.T, value string) time.Time {
+	t.Helper()
+
+	res, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatalf("parse %s: %v", value, err)
+	}
+	return res
+}
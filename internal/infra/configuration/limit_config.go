@@ -0,0 +1,92 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+
+	"github.com/go-limit/internal/core/model"
+)
+
+// Load the limit engine configuration
+func GetLimitEnv() model.LimitConfig {
+	childLogger.Info().Str("func","GetLimitEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var limitConfig	model.LimitConfig
+
+	limitConfig.Storage = "postgres"
+	limitConfig.ReservationSweepInterval = 30
+	limitConfig.LedgerBuffer = 1000
+	limitConfig.RetentionInterval = 3600
+	limitConfig.RetentionGrace = 86400
+	limitConfig.RetentionBatchSize = 1000
+
+	if os.Getenv("STORAGE") !=  "" {
+		limitConfig.Storage = os.Getenv("STORAGE")
+	}
+
+	if os.Getenv("RESERVATION_SWEEP_INTERVAL") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("RESERVATION_SWEEP_INTERVAL"))
+		limitConfig.ReservationSweepInterval = intVar
+	}
+
+	if os.Getenv("DB_MIGRATE") ==  "true" {
+		limitConfig.MigrateOnStart = true
+	} else {
+		limitConfig.MigrateOnStart = false
+	}
+
+	// optional counter store (redis), the storage becomes the ledger
+	if os.Getenv("COUNTER_STORE") !=  "" {
+		limitConfig.CounterStore = os.Getenv("COUNTER_STORE")
+	}
+	if os.Getenv("REDIS_ADDRESS") !=  "" {
+		limitConfig.RedisAddress = os.Getenv("REDIS_ADDRESS")
+	}
+	if os.Getenv("LEDGER_BUFFER") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("LEDGER_BUFFER"))
+		limitConfig.LedgerBuffer = intVar
+	}
+
+	// retention of the limit transactions out of every window
+	if os.Getenv("RETENTION_INTERVAL") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("RETENTION_INTERVAL"))
+		limitConfig.RetentionInterval = intVar
+	}
+	if os.Getenv("RETENTION_GRACE") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("RETENTION_GRACE"))
+		limitConfig.RetentionGrace = intVar
+	}
+	if os.Getenv("RETENTION_BATCH_SIZE") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("RETENTION_BATCH_SIZE"))
+		limitConfig.RetentionBatchSize = intVar
+	}
+	if os.Getenv("RETENTION_ARCHIVE") ==  "true" {
+		limitConfig.RetentionArchive = true
+	}
+
+	// fixed fx rates (ex: USD/BRL=5.4321;EUR/BRL=6.01), without them there is no conversion
+	if os.Getenv("FX_RATES") !=  "" {
+		limitConfig.FxRates = os.Getenv("FX_RATES")
+	}
+	// the currency of a check or an order limit without currency (ex: BRL)
+	if os.Getenv("BASE_CURRENCY") !=  "" {
+		limitConfig.BaseCurrency = os.Getenv("BASE_CURRENCY")
+	}
+
+	// the redis secret is optional (a local redis usually has no password)
+	file_pass, err := os.ReadFile("/var/pod/secret/redis_password")
+	if err == nil {
+		limitConfig.RedisPassword = string(file_pass)
+	} else if os.Getenv("REDIS_PASSWORD") !=  "" {
+		limitConfig.RedisPassword = os.Getenv("REDIS_PASSWORD")
+	}
+
+	return limitConfig
+}
@@ -0,0 +1,224 @@
+package migration
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+
+	go_core_pg "github.com/eliezerraj/go-core/database/pg"
+)
+
+var (
+	childLogger = log.With().Str("component","go-limit").Str("package","internal.infra.migration").Logger()
+	// all migrations are named <version>_<name>.<up|down>.sql
+	//go:embed sql/*.sql
+	migrationFS embed.FS
+)
+
+// About the lock shared by all pods, only one of them migrates at a time
+const migrationLockId = 7419001
+
+type Migration struct {
+	Version	int
+	Name	string
+	Up		string
+	Down	string
+}
+
+type Migrator struct {
+	databasePGServer	*go_core_pg.DatabasePGServer
+	migrations			[]Migration
+}
+
+// About create a migrator with the migrations embedded
+func NewMigrator(databasePGServer *go_core_pg.DatabasePGServer) (*Migrator, error) {
+	childLogger.Info().Str("func","NewMigrator").Send()
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		databasePGServer: databasePGServer,
+		migrations: migrations,
+	}, nil
+}
+
+// About load and sort the migrations embedded
+func loadMigrations() ([]Migration, error) {
+	entries, err := migrationFS.ReadDir("sql")
+	if err != nil {
+		return nil, err
+	}
+
+	by_version := map[int]*Migration{}
+	for _, entry := range entries {
+		file_name := entry.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(file_name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(file_name, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("invalid migration file name %s", file_name)
+		}
+
+		base := strings.TrimSuffix(file_name, "." + direction + ".sql")
+		version_name := strings.SplitN(base, "_", 2)
+		if len(version_name) != 2 {
+			return nil, fmt.Errorf("invalid migration file name %s", file_name)
+		}
+		version, err := strconv.Atoi(version_name[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %s", file_name)
+		}
+
+		content, err := migrationFS.ReadFile("sql/" + file_name)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := by_version[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: version_name[1]}
+			by_version[version] = migration
+		}
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := []Migration{}
+	for _, migration := range by_version {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d must have an up and a down file", migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// About prepare a transaction holding the migration lock
+// The function returned commits (or rollbacks when an error is given) and releases the connection
+func (m *Migrator) startTx(ctx context.Context) (pgx.Tx, int, func(error) error, error) {
+	tx, conn, err := m.databasePGServer.StartTx(ctx)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	finish := func(err error) error {
+		defer m.databasePGServer.ReleaseTx(conn)
+		if err != nil {
+			tx.Rollback(ctx)
+			return err
+		}
+		return tx.Commit(ctx)
+	}
+
+	_, err = tx.Exec(ctx, `select pg_advisory_xact_lock($1)`, int64(migrationLockId))
+	if err == nil {
+		_, err = tx.Exec(ctx, `create table if not exists schema_version (
+									version		integer primary key,
+									name		varchar(200) not null,
+									applied_at	timestamptz not null default now())`)
+	}
+	var version int
+	if err == nil {
+		err = tx.QueryRow(ctx, `select coalesce(max(version), 0) from schema_version`).Scan(&version)
+	}
+	if err != nil {
+		finish(err)
+		return nil, 0, nil, errors.New(err.Error())
+	}
+
+	return tx, version, finish, nil
+}
+
+// About the version of the schema
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	childLogger.Info().Str("func","Version").Send()
+
+	_, version, finish, err := m.startTx(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return version, finish(nil)
+}
+
+// About apply all the migrations not applied yet
+func (m *Migrator) Up(ctx context.Context) error {
+	childLogger.Info().Str("func","Up").Send()
+
+	for _, migration := range m.migrations {
+		tx, version, finish, err := m.startTx(ctx)
+		if err != nil {
+			return err
+		}
+		if migration.Version <= version {
+			if err = finish(nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		_, err = tx.Exec(ctx, migration.Up)
+		if err == nil {
+			_, err = tx.Exec(ctx, `insert into schema_version (version, name) values ($1, $2)`, migration.Version, migration.Name)
+		}
+		if err = finish(err); err != nil {
+			return fmt.Errorf("migration %d_%s up: %w", migration.Version, migration.Name, err)
+		}
+
+		childLogger.Info().Int("version", migration.Version).Str("name", migration.Name).Msg("migration applied")
+	}
+
+	return nil
+}
+
+// About revert the last applied migrations
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	childLogger.Info().Str("func","Down").Int("steps", steps).Send()
+
+	for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+		migration := m.migrations[i]
+
+		tx, version, finish, err := m.startTx(ctx)
+		if err != nil {
+			return err
+		}
+		if migration.Version > version {
+			if err = finish(nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		_, err = tx.Exec(ctx, migration.Down)
+		if err == nil {
+			_, err = tx.Exec(ctx, `delete from schema_version where version = $1`, migration.Version)
+		}
+		if err = finish(err); err != nil {
+			return fmt.Errorf("migration %d_%s down: %w", migration.Version, migration.Name, err)
+		}
+
+		childLogger.Info().Int("version", migration.Version).Str("name", migration.Name).Msg("migration reverted")
+		steps = steps - 1
+	}
+
+	return nil
+}
@@ -0,0 +1,5 @@
+drop table if exists limit_request;
+drop table if exists limit_transaction;
+drop table if exists order_limit;
+drop table if exists counter_limit;
+drop table if exists type_limit;
@@ -0,0 +1,58 @@
+create table if not exists type_limit (
+    code                    varchar(100) primary key,
+    category                varchar(100) not null,
+    created_at              timestamptz not null default now()
+);
+
+create table if not exists counter_limit (
+    code                    varchar(100) primary key,
+    created_at              timestamptz not null default now()
+);
+
+insert into counter_limit (code) values ('VALUE'), ('QUANTITY'), ('SECOND'), ('MINUTE'), ('HOUR')
+on conflict do nothing;
+
+create table if not exists order_limit (
+    id                      serial primary key,
+    fk_type_limit_code      varchar(100) not null references type_limit(code),
+    fk_counter_limit_code   varchar(100) not null references counter_limit(code),
+    type                    varchar(100) not null,
+    amount                  integer not null,
+    time_window             varchar(50) not null default 'MINUTE',
+    created_at              timestamptz not null default now(),
+    unique (fk_type_limit_code, type, fk_counter_limit_code, time_window)
+);
+
+create table if not exists limit_transaction (
+    id                      bigserial primary key,
+    transaction_id          varchar(100) not null,
+    key                     varchar(200) not null,
+    fk_type_limit_code      varchar(100) not null,
+    fk_counter_limit_code   varchar(100) not null,
+    fk_order_limit_type     varchar(100) not null,
+    status                  varchar(100) not null,
+    amount                  numeric(18,2) not null,
+    reversed_amount         numeric(18,2) not null default 0,
+    reversed_at             timestamptz,
+    expires_at              timestamptz,
+    fk_limit_transaction_id bigint references limit_transaction(id),
+    time_window             varchar(50) not null,
+    created_at              timestamptz not null
+);
+
+create index if not exists limit_transaction_window_idx
+    on limit_transaction (key, fk_type_limit_code, fk_order_limit_type, fk_counter_limit_code, time_window, created_at);
+
+create index if not exists limit_transaction_transaction_id_idx
+    on limit_transaction (transaction_id);
+
+create index if not exists limit_transaction_reserved_idx
+    on limit_transaction (expires_at)
+    where status like '%:RESERVED';
+
+create table if not exists limit_request (
+    transaction_id          varchar(100) primary key,
+    payload_hash            varchar(64) not null,
+    response                jsonb not null,
+    created_at              timestamptz not null
+);
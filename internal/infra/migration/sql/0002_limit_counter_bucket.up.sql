@@ -0,0 +1,31 @@
+create table if not exists limit_counter_bucket (
+    key                     varchar(200) not null,
+    fk_type_limit_code      varchar(100) not null,
+    fk_order_limit_type     varchar(100) not null,
+    fk_counter_limit_code   varchar(100) not null,
+    time_window             varchar(50) not null,
+    bucket_start            timestamptz not null,
+    amount                  numeric(18,2) not null default 0,
+    quantity                integer not null default 0,
+    first_created_at        timestamptz not null,
+    primary key (key, fk_type_limit_code, fk_order_limit_type, fk_counter_limit_code, time_window, bucket_start)
+);
+
+-- the reservations alive are read from limit_transaction, only the settled consumption is bucketed
+insert into limit_counter_bucket (key, fk_type_limit_code, fk_order_limit_type, fk_counter_limit_code, time_window, bucket_start, amount, quantity, first_created_at)
+select key,
+       fk_type_limit_code,
+       fk_order_limit_type,
+       fk_counter_limit_code,
+       time_window,
+       date_trunc('minute', created_at),
+       sum(amount),
+       count(1),
+       min(created_at)
+from limit_transaction
+where status not like '%:BREACH'
+and status not like '%:RESERVED'
+and status not like '%:RELEASED'
+and status not like '%:EXPIRED'
+group by 1, 2, 3, 4, 5, 6
+on conflict do nothing;
@@ -0,0 +1,3 @@
+drop table if exists limit_transaction_archive;
+
+drop index if exists limit_transaction_created_at_idx;
@@ -0,0 +1,5 @@
+create index if not exists limit_transaction_created_at_idx
+    on limit_transaction (created_at);
+
+-- the rows purged by the retention are moved here when the archive is enabled (no constraints)
+create table if not exists limit_transaction_archive (like limit_transaction);
@@ -0,0 +1 @@
+alter table order_limit alter column amount type integer using round(amount)::integer;
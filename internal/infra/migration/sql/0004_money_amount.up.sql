@@ -0,0 +1,2 @@
+-- the limit amount is money (2 fractional digits), like the amounts of limit_transaction
+alter table order_limit alter column amount type numeric(18,2);
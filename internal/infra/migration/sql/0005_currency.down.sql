@@ -0,0 +1,11 @@
+alter table limit_transaction_archive drop column if exists fx_rate;
+alter table limit_transaction_archive drop column if exists original_currency;
+alter table limit_transaction_archive drop column if exists original_amount;
+alter table limit_transaction_archive drop column if exists currency;
+
+alter table limit_transaction drop column if exists fx_rate;
+alter table limit_transaction drop column if exists original_currency;
+alter table limit_transaction drop column if exists original_amount;
+alter table limit_transaction drop column if exists currency;
+
+alter table order_limit drop column if exists currency;
@@ -0,0 +1,14 @@
+-- the currency of an order limit, empty means any currency (no conversion)
+alter table order_limit add column if not exists currency varchar(3) not null default '';
+
+-- the amount of a limit transaction is in the currency of the order limit, the amount received and the rate are kept
+alter table limit_transaction add column if not exists currency varchar(3) not null default '';
+alter table limit_transaction add column if not exists original_amount numeric(18,2);
+alter table limit_transaction add column if not exists original_currency varchar(3) not null default '';
+alter table limit_transaction add column if not exists fx_rate numeric(24,10);
+
+-- the archive keeps the same columns of limit_transaction
+alter table limit_transaction_archive add column if not exists currency varchar(3) not null default '';
+alter table limit_transaction_archive add column if not exists original_amount numeric(18,2);
+alter table limit_transaction_archive add column if not exists original_currency varchar(3) not null default '';
+alter table limit_transaction_archive add column if not exists fx_rate numeric(24,10);
@@ -0,0 +1,10 @@
+-- the limit of an order limit for a single key (ex: a card), it replaces the amount of the default until it expires
+create table if not exists limit_override (
+    id                      serial primary key,
+    key                     varchar(200) not null,
+    fk_order_limit_id       integer not null references order_limit(id) on delete cascade,
+    amount                  numeric(18,2) not null,
+    expires_at              timestamptz,
+    created_at              timestamptz not null default now(),
+    unique (key, fk_order_limit_id)
+);
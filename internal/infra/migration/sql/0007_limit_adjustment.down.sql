@@ -0,0 +1,4 @@
+alter table limit_transaction_archive drop column if exists adjustment_id;
+alter table limit_transaction drop column if exists adjustment_id;
+
+drop table if exists limit_adjustment;
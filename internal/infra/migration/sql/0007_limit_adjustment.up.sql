@@ -0,0 +1,20 @@
+-- a temporary adjustment of an order limit for a single key (ex: a travel raise), active from starts_at until ends_at
+-- ABSOLUTE adds the value to the amount, PERCENTAGE adds a percentage of the amount (the value may be negative)
+create table if not exists limit_adjustment (
+    id                      serial primary key,
+    key                     varchar(200) not null,
+    fk_order_limit_id       integer not null references order_limit(id) on delete cascade,
+    type                    varchar(20) not null,
+    value                   numeric(18,2) not null,
+    starts_at               timestamptz not null,
+    ends_at                 timestamptz not null,
+    created_at              timestamptz not null default now(),
+    check (ends_at > starts_at)
+);
+
+create index if not exists limit_adjustment_key_idx
+    on limit_adjustment (key, ends_at);
+
+-- the adjustment in force when the limit transaction was evaluated (kept even after the adjustment is deleted)
+alter table limit_transaction add column if not exists adjustment_id integer;
+alter table limit_transaction_archive add column if not exists adjustment_id integer;
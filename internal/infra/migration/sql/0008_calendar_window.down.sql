@@ -0,0 +1,2 @@
+alter table type_limit drop column if exists billing_cycle_day;
+alter table type_limit drop column if exists timezone;
@@ -0,0 +1,5 @@
+-- the calendar windows (CALENDAR_DAY, CALENDAR_MONTH) of a type limit are aligned on its timezone (IANA, ex: America/Sao_Paulo)
+-- a CALENDAR_MONTH resets on the billing cycle day (the last day of a shorter month)
+alter table type_limit add column if not exists timezone varchar(64) not null default 'UTC';
+alter table type_limit add column if not exists billing_cycle_day smallint not null default 1
+    check (billing_cycle_day between 1 and 31);
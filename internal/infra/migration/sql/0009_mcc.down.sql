@@ -0,0 +1,31 @@
+-- the buckets of every mcc are merged back
+alter table limit_counter_bucket drop constraint if exists limit_counter_bucket_pkey;
+with merged as (
+    delete from limit_counter_bucket
+    returning *
+)
+insert into limit_counter_bucket (key, fk_type_limit_code, fk_order_limit_type, fk_counter_limit_code, time_window, bucket_start, amount, quantity, first_created_at)
+select key,
+       fk_type_limit_code,
+       fk_order_limit_type,
+       fk_counter_limit_code,
+       time_window,
+       bucket_start,
+       sum(amount),
+       sum(quantity),
+       min(first_created_at)
+from merged
+group by 1, 2, 3, 4, 5, 6;
+alter table limit_counter_bucket drop column if exists mcc;
+alter table limit_counter_bucket add primary key (key, fk_type_limit_code, fk_order_limit_type, fk_counter_limit_code, time_window, bucket_start);
+
+alter table limit_transaction_archive drop column if exists mcc;
+alter table limit_transaction drop column if exists mcc;
+
+-- the order limits of a mcc can not exist without the dimension
+alter table order_limit drop constraint if exists order_limit_mcc_key;
+delete from order_limit where mcc <> '';
+alter table order_limit drop column if exists mcc;
+alter table order_limit add unique (fk_type_limit_code, type, fk_counter_limit_code, time_window);
+
+drop table if exists mcc_group;
@@ -0,0 +1,34 @@
+-- a named list of merchant category codes (ex: RESTAURANTS), an order limit may refer to a group instead of a single mcc
+create table if not exists mcc_group (
+    code                    varchar(100) primary key,
+    mccs                    varchar(100)[] not null,
+    created_at              timestamptz not null default now()
+);
+
+-- the mcc (or mcc group) of an order limit, empty is the wildcard used when nothing more specific matches
+alter table order_limit add column if not exists mcc varchar(100) not null default '';
+
+do $$
+declare
+    constraint_name text;
+begin
+    select conname into constraint_name
+    from pg_constraint
+    where conrelid = 'order_limit'::regclass
+    and contype = 'u'
+    and pg_get_constraintdef(oid) = 'UNIQUE (fk_type_limit_code, type, fk_counter_limit_code, time_window)';
+
+    if constraint_name is not null then
+        execute format('alter table order_limit drop constraint %I', constraint_name);
+    end if;
+end $$;
+
+alter table order_limit add constraint order_limit_mcc_key unique (fk_type_limit_code, type, fk_counter_limit_code, mcc, time_window);
+
+-- the mcc of the transaction, the usage of an order limit of a mcc (or group) only counts the matching ones
+alter table limit_transaction add column if not exists mcc varchar(100) not null default '';
+alter table limit_transaction_archive add column if not exists mcc varchar(100) not null default '';
+
+alter table limit_counter_bucket add column if not exists mcc varchar(100) not null default '';
+alter table limit_counter_bucket drop constraint if exists limit_counter_bucket_pkey;
+alter table limit_counter_bucket add primary key (key, fk_type_limit_code, fk_order_limit_type, fk_counter_limit_code, mcc, time_window, bucket_start);
@@ -0,0 +1,16 @@
+-- the consumption of the rules is dropped, it was never counted by the other order limits
+alter table limit_counter_bucket drop constraint if exists limit_counter_bucket_pkey;
+delete from limit_counter_bucket where rule_id <> 0;
+alter table limit_counter_bucket drop column if exists rule_id;
+alter table limit_counter_bucket add primary key (key, fk_type_limit_code, fk_order_limit_type, fk_counter_limit_code, mcc, time_window, bucket_start);
+
+delete from limit_transaction_archive where rule_id <> 0;
+alter table limit_transaction_archive drop column if exists rule_id;
+delete from limit_transaction where rule_id <> 0;
+alter table limit_transaction drop column if exists rule_id;
+
+-- the rules can not exist without the conditions
+alter table order_limit drop constraint if exists order_limit_rule_key;
+delete from order_limit where conditions <> '[]';
+alter table order_limit drop column if exists conditions;
+alter table order_limit add constraint order_limit_mcc_key unique (fk_type_limit_code, type, fk_counter_limit_code, mcc, time_window);
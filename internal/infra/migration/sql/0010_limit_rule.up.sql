@@ -0,0 +1,14 @@
+-- the conditions of an order limit on the attributes of the check (ex: channel EQ ECOMMERCE), empty applies to every check
+-- an order limit with conditions is a rule, evaluated besides the other order limits of the counter
+alter table order_limit add column if not exists conditions jsonb not null default '[]';
+
+alter table order_limit drop constraint if exists order_limit_mcc_key;
+alter table order_limit add constraint order_limit_rule_key unique (fk_type_limit_code, type, fk_counter_limit_code, mcc, conditions, time_window);
+
+-- the rule that counted the limit transaction (0 for an order limit without conditions), the usage of a rule only counts its own
+alter table limit_transaction add column if not exists rule_id integer not null default 0;
+alter table limit_transaction_archive add column if not exists rule_id integer not null default 0;
+
+alter table limit_counter_bucket add column if not exists rule_id integer not null default 0;
+alter table limit_counter_bucket drop constraint if exists limit_counter_bucket_pkey;
+alter table limit_counter_bucket add primary key (key, fk_type_limit_code, fk_order_limit_type, fk_counter_limit_code, mcc, rule_id, time_window, bucket_start);
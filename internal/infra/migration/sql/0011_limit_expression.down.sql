@@ -0,0 +1,9 @@
+-- the consumption of the rules with an expression is dropped with them
+delete from limit_counter_bucket where rule_id in (select id from order_limit where expression <> '');
+delete from limit_transaction_archive where rule_id in (select id from order_limit where expression <> '');
+delete from limit_transaction where rule_id in (select id from order_limit where expression <> '');
+
+alter table order_limit drop constraint if exists order_limit_expression_key;
+delete from order_limit where expression <> '';
+alter table order_limit drop column if exists expression;
+alter table order_limit add constraint order_limit_rule_key unique (fk_type_limit_code, type, fk_counter_limit_code, mcc, conditions, time_window);
@@ -0,0 +1,6 @@
+-- the expression of an order limit on the check and the time of day (ex: amount > 100 and channel = 'ATM'), empty applies to every check
+-- an order limit with an expression is a rule, as an order limit with conditions
+alter table order_limit add column if not exists expression text not null default '';
+
+alter table order_limit drop constraint if exists order_limit_rule_key;
+alter table order_limit add constraint order_limit_expression_key unique (fk_type_limit_code, type, fk_counter_limit_code, mcc, conditions, expression, time_window);
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+
+	go_core_observ "github.com/eliezerraj/go-core/observability"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	sdk_metric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// About create the meter provider of the metrics of the service (ex: the retention)
+// The metrics go to the same exporter of the traces, the otlp collector or the stdout (nil without an exporter)
+func newMeterProvider(	ctx context.Context,
+						configOTEL *go_core_observ.ConfigOTEL,
+						infoTrace *go_core_observ.InfoTrace) *sdk_metric.MeterProvider {
+	childLogger.Info().Str("func","newMeterProvider").Send()
+
+	var exporter sdk_metric.Exporter
+	var err error
+
+	switch {
+	case configOTEL.UseOtlpCollector:
+		exporter, err = otlpmetricgrpc.New(ctx,
+											otlpmetricgrpc.WithEndpoint(configOTEL.OtelExportEndpoint),
+											otlpmetricgrpc.WithInsecure())
+	case configOTEL.UseStdoutTracerExporter:
+		exporter, err = stdoutmetric.New()
+	default:
+		return nil
+	}
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error create the metric exporter")
+		return nil
+	}
+
+	res := resource.NewSchemaless(	attribute.String("service.name", infoTrace.PodName),
+									attribute.String("service.version", infoTrace.PodVersion))
+
+	return sdk_metric.NewMeterProvider(	sdk_metric.WithReader(sdk_metric.NewPeriodicReader(exporter)),
+										sdk_metric.WithResource(res))
+}
@@ -66,6 +66,12 @@ func (h HttpServer) StartHttpAppServer(	ctx context.Context,
 		otel.SetTracerProvider(tp)
 		tracer = tp.Tracer(appServer.InfoPod.PodName)
 	}
+
+	// the metrics (ex: the retention) are created on the global meter provider
+	mp := newMeterProvider(ctx, appServer.ConfigOTEL, &infoTrace)
+	if mp != nil {
+		otel.SetMeterProvider(mp)
+	}
 	
 	defer func() { 
 		if tp != nil {
@@ -74,6 +80,12 @@ func (h HttpServer) StartHttpAppServer(	ctx context.Context,
 				childLogger.Error().Err(err).Send()
 			}
 		}
+		if mp != nil {
+			err := mp.Shutdown(ctx)
+			if err != nil{
+				childLogger.Error().Err(err).Send()
+			}
+		}
 		childLogger.Info().Msg("stop done !!!")
 	}()
 	
@@ -111,6 +123,59 @@ func (h HttpServer) StartHttpAppServer(	ctx context.Context,
 	addTransactionLimit.HandleFunc("/checkLimitTransaction", core_middleware.MiddleWareErrorHandler(httpRouters.CheckLimitTransaction))		
 	addTransactionLimit.Use(otelmux.Middleware("go-limit"))
 
+	simulateTransactionLimit := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	simulateTransactionLimit.HandleFunc("/simulateLimitTransaction", core_middleware.MiddleWareErrorHandler(httpRouters.SimulateLimitTransaction))		
+	simulateTransactionLimit.Use(otelmux.Middleware("go-limit"))
+
+	reverseTransactionLimit := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	reverseTransactionLimit.HandleFunc("/reverseLimitTransaction", core_middleware.MiddleWareErrorHandler(httpRouters.ReverseLimitTransaction))		
+	reverseTransactionLimit.Use(otelmux.Middleware("go-limit"))
+
+	confirmTransactionLimit := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	confirmTransactionLimit.HandleFunc("/confirmLimitTransaction", core_middleware.MiddleWareErrorHandler(httpRouters.ConfirmLimitTransaction))		
+	confirmTransactionLimit.Use(otelmux.Middleware("go-limit"))
+
+	releaseTransactionLimit := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	releaseTransactionLimit.HandleFunc("/releaseLimitTransaction", core_middleware.MiddleWareErrorHandler(httpRouters.ReleaseLimitTransaction))		
+	releaseTransactionLimit.Use(otelmux.Middleware("go-limit"))
+
+	getLimitConfig := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	getLimitConfig.HandleFunc("/typeLimit", core_middleware.MiddleWareErrorHandler(httpRouters.ListTypeLimit))
+	getLimitConfig.HandleFunc("/typeLimit/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.GetTypeLimit))
+	getLimitConfig.HandleFunc("/orderLimit", core_middleware.MiddleWareErrorHandler(httpRouters.ListOrderLimit))
+	getLimitConfig.HandleFunc("/orderLimit/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.GetOrderLimit))
+	getLimitConfig.HandleFunc("/limitOverride", core_middleware.MiddleWareErrorHandler(httpRouters.ListLimitOverride))
+	getLimitConfig.HandleFunc("/limitOverride/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.GetLimitOverride))
+	getLimitConfig.HandleFunc("/limitAdjustment", core_middleware.MiddleWareErrorHandler(httpRouters.ListLimitAdjustment))
+	getLimitConfig.HandleFunc("/limitAdjustment/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.GetLimitAdjustment))
+	getLimitConfig.HandleFunc("/mccGroup", core_middleware.MiddleWareErrorHandler(httpRouters.ListMccGroup))
+	getLimitConfig.HandleFunc("/mccGroup/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.GetMccGroup))
+	getLimitConfig.Use(otelmux.Middleware("go-limit"))
+
+	addLimitConfig := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	addLimitConfig.HandleFunc("/typeLimit", core_middleware.MiddleWareErrorHandler(httpRouters.AddTypeLimit))
+	addLimitConfig.HandleFunc("/orderLimit", core_middleware.MiddleWareErrorHandler(httpRouters.AddOrderLimit))
+	addLimitConfig.HandleFunc("/limitOverride", core_middleware.MiddleWareErrorHandler(httpRouters.AddLimitOverride))
+	addLimitConfig.HandleFunc("/limitAdjustment", core_middleware.MiddleWareErrorHandler(httpRouters.AddLimitAdjustment))
+	addLimitConfig.HandleFunc("/mccGroup", core_middleware.MiddleWareErrorHandler(httpRouters.AddMccGroup))
+	addLimitConfig.Use(otelmux.Middleware("go-limit"))
+
+	updateLimitConfig := myRouter.Methods(http.MethodPut, http.MethodOptions).Subrouter()
+	updateLimitConfig.HandleFunc("/typeLimit/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.UpdateTypeLimit))
+	updateLimitConfig.HandleFunc("/orderLimit/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.UpdateOrderLimit))
+	updateLimitConfig.HandleFunc("/limitOverride/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.UpdateLimitOverride))
+	updateLimitConfig.HandleFunc("/limitAdjustment/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.UpdateLimitAdjustment))
+	updateLimitConfig.HandleFunc("/mccGroup/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.UpdateMccGroup))
+	updateLimitConfig.Use(otelmux.Middleware("go-limit"))
+
+	deleteLimitConfig := myRouter.Methods(http.MethodDelete, http.MethodOptions).Subrouter()
+	deleteLimitConfig.HandleFunc("/typeLimit/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.DeleteTypeLimit))
+	deleteLimitConfig.HandleFunc("/orderLimit/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.DeleteOrderLimit))
+	deleteLimitConfig.HandleFunc("/limitOverride/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.DeleteLimitOverride))
+	deleteLimitConfig.HandleFunc("/limitAdjustment/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.DeleteLimitAdjustment))
+	deleteLimitConfig.HandleFunc("/mccGroup/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.DeleteMccGroup))
+	deleteLimitConfig.Use(otelmux.Middleware("go-limit"))
+
 	srv := http.Server{
 		Addr:         ":" +  strconv.Itoa(h.httpServer.Port),      	
 		Handler:      myRouter,                	          